@@ -0,0 +1,110 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+import "C"
+import (
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// BitlkParams is used for opening BitLocker devices. These can only
+// be loaded, there is nothing to configure. Once loaded, the device
+// is activated with Activate, ActivateWithFlags or ActivateWithSlot
+// using either the passphrase or the recovery key (in its usual
+// dash-separated form) as pass. The mapping is always read-only,
+// ActivateReadOnly is added to the flags.
+type BitlkParams struct{}
+
+func (p BitlkParams) CMode() (t string, pp Params, out unsafe.Pointer, free func()) {
+	return C.CRYPT_BITLK, pp, nil, func() {}
+}
+
+// BitlkInfo describes the metadata of a BitLocker device loaded with
+// BitlkParams.
+type BitlkInfo struct {
+	Version     int
+	Guid        string
+	Description string
+	Cipher      string // e.g. "aes"
+	Mode        string // e.g. "xts-plain64"
+	KeySize     uint64 // volume key size (in bytes)
+	Protectors  []BitlkProtector
+}
+
+// BitlkProtector is a key protector of a BitLocker device, it is
+// reported by the library as a keyslot.
+type BitlkProtector struct {
+	Slot int
+	Name string // friendly name or ""
+	Guid string
+
+	// Protection is the type of the protector as described by
+	// the library, e.g. "VMK protected with passphrase" or "VMK
+	// protected with recovery passphrase".
+	Protection string
+}
+
+// BitlkInfo returns the metadata of the BitLocker device loaded with
+// BitlkParams.
+func (d *Device) BitlkInfo() (BitlkInfo, error) {
+	lines, err := d.dumpLines()
+	if err != nil {
+		return BitlkInfo{}, err
+	}
+	return parseBitlkInfo(lines), nil
+}
+
+// parseBitlkInfo reads the metadata out of the library's dump of a
+// BitLocker device.
+func parseBitlkInfo(lines []string) (info BitlkInfo) {
+	var p *BitlkProtector
+	keyslots := false
+	for _, l := range lines {
+		k, v, ok := dumpField(l)
+		if !ok {
+			continue
+		}
+		switch {
+		case k == "Keyslots":
+			keyslots = true
+		case k == "Metadata segments":
+			keyslots = false
+		case !keyslots:
+			switch k {
+			case "Version":
+				info.Version, _ = strconv.Atoi(v)
+			case "GUID":
+				info.Guid = v
+			case "Description":
+				info.Description = v
+			case "Cipher name":
+				info.Cipher = v
+			case "Cipher mode":
+				info.Mode = v
+			case "Cipher key":
+				bits, _ := strconv.ParseUint(strings.TrimSuffix(v, " bits"), 10, 64)
+				info.KeySize = bits / 8
+			}
+		case v == "VMK":
+			slot, _ := strconv.Atoi(k)
+			info.Protectors = append(info.Protectors, BitlkProtector{Slot: slot})
+			p = &info.Protectors[len(info.Protectors)-1]
+		case v == "FVEK":
+			// the full volume encryption key is not a
+			// protector
+			p = nil
+		case p != nil:
+			switch k {
+			case "Name":
+				p.Name = v
+			case "GUID":
+				p.Guid = v
+			case "Protection":
+				p.Protection = v
+			}
+		}
+	}
+	return
+}
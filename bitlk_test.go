@@ -0,0 +1,112 @@
+package cryptsetup
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDevice_Bitlk_error(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Load(BitlkParams{})
+	if err == nil {
+		t.Fatal("loaded a BitLocker header from an empty device")
+	}
+	err = d.Load(Fvault2Params{})
+	if err == nil {
+		t.Fatal("loaded a FileVault2 header from an empty device")
+	}
+}
+
+func Test_parseBitlkInfo(t *testing.T) {
+	t.Parallel()
+
+	dump := "Info for BITLK device /dev/loop0.\n" +
+		"Version:      \t2\n" +
+		"GUID:         \t4a8a2bd4-6b05-4b9f-9a0f-6f5b1e0c3e11\n" +
+		"Sector size:  \t512 [bytes]\n" +
+		"Volume size:  \t104857600 [bytes]\n" +
+		"Created:      \tWed Oct 14 12:00:00 2020\n" +
+		"Description:  \tDESKTOP E: 10/14/2020\n" +
+		"Cipher name:  \taes\n" +
+		"Cipher mode:  \txts-plain64\n" +
+		"Cipher key:   \t128 bits\n" +
+		"\n" +
+		"Keyslots:\n" +
+		" 0: VMK\n" +
+		"\tGUID:       \t8f54c3a0-9a39-4e4c-8c5b-1c8a7f3c0b01\n" +
+		"\tProtection: \tVMK protected with passphrase\n" +
+		"\tSalt:       \t00112233445566778899aabbccddeeff\n" +
+		"\tKey data size:\t44 [bytes]\n" +
+		" 1: VMK\n" +
+		"\tGUID:       \t0b7e2a9c-5d41-4b2e-a1a7-52d9a8d3b202\n" +
+		"\tProtection: \tVMK protected with recovery passphrase\n" +
+		"\tSalt:       \t00112233445566778899aabbccddeeff\n" +
+		"\tKey data size:\t44 [bytes]\n" +
+		" 2: FVEK\n" +
+		"\tKey data size:\t44 [bytes]\n" +
+		"\n" +
+		"Metadata segments:\n" +
+		" 0: FVE metadata area\n" +
+		"\tOffset: \t35651584 [bytes]\n"
+
+	want := BitlkInfo{
+		Version:     2,
+		Guid:        "4a8a2bd4-6b05-4b9f-9a0f-6f5b1e0c3e11",
+		Description: "DESKTOP E: 10/14/2020",
+		Cipher:      "aes",
+		Mode:        "xts-plain64",
+		KeySize:     16,
+		Protectors: []BitlkProtector{
+			{
+				Slot:       0,
+				Guid:       "8f54c3a0-9a39-4e4c-8c5b-1c8a7f3c0b01",
+				Protection: "VMK protected with passphrase",
+			},
+			{
+				Slot:       1,
+				Guid:       "0b7e2a9c-5d41-4b2e-a1a7-52d9a8d3b202",
+				Protection: "VMK protected with recovery passphrase",
+			},
+		},
+	}
+	got := parseBitlkInfo(strings.Split(dump, "\n"))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func Test_parseFvault2Info(t *testing.T) {
+	t.Parallel()
+
+	dump := "Header information for FVAULT2 device /dev/loop0.\n" +
+		"Physical volume UUID: \t6f2d2e9c-8c1c-4f6e-9d55-0c1b2d3e4f50\n" +
+		"Family UUID:          \t1e0d9c8b-7a6f-4e5d-8c4b-3a2f1e0d9c8b\n" +
+		"Logical volume offset:\t67108864 [bytes]\n" +
+		"Logical volume size:  \t104857600 [bytes]\n" +
+		"Cipher:               \taes\n" +
+		"Cipher mode:          \txts-plain64\n" +
+		"PBKDF2 iterations:    \t41000\n" +
+		"PBKDF2 salt:          \t00 11 22 33 44 55 66 77 88 99 aa bb cc dd ee ff\n"
+
+	want := Fvault2Info{
+		PhysicalVolumeUuid:  "6f2d2e9c-8c1c-4f6e-9d55-0c1b2d3e4f50",
+		FamilyUuid:          "1e0d9c8b-7a6f-4e5d-8c4b-3a2f1e0d9c8b",
+		LogicalVolumeOffset: 67108864,
+		LogicalVolumeSize:   104857600,
+		Cipher:              "aes",
+		Mode:                "xts-plain64",
+		PbkdfIterations:     41000,
+	}
+	got := parseFvault2Info(strings.Split(dump, "\n"))
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -4,7 +4,10 @@ package cryptsetup
 // #include <libcryptsetup.h>
 import "C"
 import (
+	"strings"
+	"syscall"
 	"time"
+	"unsafe"
 )
 
 // Device is a handle on the crypto device. It corresponds to a
@@ -45,33 +48,96 @@ func (d *Device) Load(p CryptParameter) error {
 	return d.load(&t, params)
 }
 
+// HeaderBackup saves the device header and keyslots to the file at
+// path, which must not exist yet. The type of header to back up is
+// taken from p, if p is nil any LUKS header is backed up.
+func (d *Device) HeaderBackup(path string, p CryptParameter) error {
+	return d.headerBackup(paramType(p), path)
+}
+
+// HeaderRestore overwrites the device header and keyslots with the
+// backup saved at path by HeaderBackup. The type of header to restore
+// is taken from p, if p is nil any LUKS header is restored.
+func (d *Device) HeaderRestore(path string, p CryptParameter) error {
+	return d.headerRestore(paramType(p), path)
+}
+
+// paramType returns the device type that p describes or nil if p is
+// nil.
+func paramType(p CryptParameter) *string {
+	if p == nil {
+		return nil
+	}
+	t, _, _, free := p.CMode()
+	free()
+	return &t
+}
+
 // Format formats the block device
 func (d *Device) Format(key []byte, p CryptParameter) error {
+	return d.FormatVolumeKey(key, nil, p)
+}
+
+// FormatVolumeKey formats the block device like Format, but encrypts
+// it with the given volume key instead of a randomly generated one.
+// If volumeKey is nil a random key is generated.
+func (d *Device) FormatVolumeKey(key, volumeKey []byte, p CryptParameter) error {
 	if _, ok := <-firstInitStatus; ok {
 		defer close(firstInitStatus)
 	}
 
 	t, pp, params, free := p.CMode()
 	defer free()
+	if volumeKey != nil {
+		pp.VolumeKeySize = uint64(len(volumeKey))
+	}
 	err := d.format(
 		t,
 		pp.Cipher,
 		pp.Mode,
 		nil,		  // generate the uuid
-		nil,		  // generate the volume key
-		pp.VolumeKeySize, // 256bit volume key
+		volumeKey,	  // generate the volume key if nil
+		pp.VolumeKeySize, // in bytes
 		params,
 	)
-	if err != nil {
+	if err != nil || t == C.CRYPT_INTEGRITY || t == C.CRYPT_VERITY || t == C.CRYPT_LOOPAES {
+		// integrity, verity and loop-AES devices have no
+		// keyslots
 		return err
 	}
-	_, err = d.keyslotAddByPassphrase(
+	_, err = d.keyslotAddByVolumeKey(
 		C.CRYPT_ANY_SLOT, // use the first available key slot
 		nil,		  // use the saved volume key from
 				  // formatting
 		key,		  // the key we were passed
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if lp, ok := p.(Luks2Params); ok && lp.Integrity != "" && !lp.NoWipe {
+		return d.wipeIntegrity(key)
+	}
+	return nil
+}
+
+// wipeIntegrity initializes the integrity tags of a freshly formatted
+// device by zeroing its data through a temporary mapping, until then
+// reading any sector fails the integrity check.
+func (d *Device) wipeIntegrity(pass []byte) error {
+	name := "temporary-gocrypt-" + d.Uuid()
+	_, err := d.ActivateWithSlot(name, AnySlot, pass, ActivatePrivate|ActivateNoJournal)
+	if err != nil {
+		return err
+	}
+	defer d.Deactivate(name)
+	return d.wipe(
+		Dir()+"/"+name,
+		C.CRYPT_WIPE_ZERO,
+		0,     // from the start
+		0,     // to the end of the device
+		1<<20, // in 1MiB blocks
+		C.CRYPT_WIPE_NO_DIRECT_IO,
+	)
 }
 
 // Benchmark runs the library's internal benchmarking code on the
@@ -95,17 +161,29 @@ func (d *Device) Benchmark(iv_size uint64, buffer_size uint64, pp Params) (enc f
 }
 
 // BenchmarkKdf runs the the library's internal benchmark code for the
-// Key Deriviation Function. It returns the number hashes performed in
-// one second on the password with the given salt.
+// Key Deriviation Function described by p. It returns p with the
+// costs tuned so that deriving a key from the password with the given
+// salt takes p.Time (one second if unset).
 //
-// The number of hashes indicates the difficulty of bruteforcing the
-// password; higher is more difficult to crack.
-func (d *Device) BenchmarkKdf(hash string, pass, salt []byte) (iter uint64, err error) {
-	if hash == "" {
-		hash = DefaultHash
+// For pbkdf2 the tuned cost is the number of iterations, for argon2
+// it is the time cost, memory cost and number of threads. Higher
+// costs make the password more difficult to bruteforce.
+func (d *Device) BenchmarkKdf(p PbkdfParams, pass, salt []byte) (PbkdfParams, error) {
+	if p.Type == "" {
+		p.Type = KdfPbkdf2
 	}
-	err = d.benchmarkKdf("pbkdf2", hash, pass, salt, (*C.uint64_t)(&iter))
-	return
+	if p.Time == 0 {
+		p.Time = time.Second
+	}
+	p.def()
+
+	s, free := p.c()
+	defer free()
+	err := d.benchmarkPbkdf(s, pass, salt, 256/8)
+	if err != nil {
+		return p, err
+	}
+	return newPbkdfParams(s), nil
 }
 
 // Dir returns the directory were the decrypted devices are placed.
@@ -116,13 +194,94 @@ func Dir() string {
 // Activate sets up the encrypted volume as name under the directory
 // specified by Dir().
 func (d *Device) Activate(name string, pass []byte) error {
-	_, err := d.activateByPassphrase(
+	return d.ActivateWithFlags(name, pass, 0)
+}
+
+// ActivateWithFlags is like Activate, but sets up the mapping with
+// the given flags, e.g. ActivateAllowDiscards for SSDs.
+func (d *Device) ActivateWithFlags(name string, pass []byte, flags ActivateFlags) error {
+	_, err := d.ActivateWithSlot(name, AnySlot, pass, flags)
+	return err
+}
+
+// ActivateWithSlot is like ActivateWithFlags, but only tries to
+// unlock keyslot slot with pass. It returns the keyslot that was
+// unlocked, which is useful when slot is AnySlot.
+func (d *Device) ActivateWithSlot(name string, slot int, pass []byte, flags ActivateFlags) (int, error) {
+	return d.activateByPassphrase(
 		&name,
-		C.CRYPT_ANY_SLOT,
+		slot,
 		pass,
-		0,
+		d.activateFlags(flags),
 	)
-	return err
+}
+
+// activateFlags returns flags as passed to the library, BitLocker and
+// FileVault2 devices are always activated read-only.
+func (d *Device) activateFlags(flags ActivateFlags) uint32 {
+	switch C.GoString(C.crypt_get_type(d.cd)) {
+	case C.CRYPT_BITLK, C.CRYPT_FVAULT2:
+		flags |= ActivateReadOnly
+	}
+	return uint32(flags)
+}
+
+// ActivateByKeyring is like ActivateWithSlot, but reads the
+// passphrase from the "user" key with the given description in the
+// kernel keyring (see the keyring subpackage). If name is nil the
+// passphrase is only checked and nothing is activated.
+func (d *Device) ActivateByKeyring(name *string, keyDescription string, slot int, flags ActivateFlags) (int, error) {
+	return d.activateByKeyring(name, keyDescription, slot, d.activateFlags(flags))
+}
+
+// SetVolumeKeyKeyring decides whether the volume key of LUKS2
+// devices is handed to dm-crypt through the kernel keyring instead of
+// the device-mapper table. It is enabled by default when the kernel
+// supports it.
+func (d *Device) SetVolumeKeyKeyring(enable bool) error {
+	e := 0
+	if enable {
+		e = 1
+	}
+	return d.volumeKeyKeyring(e)
+}
+
+// ActivateByVolumeKey sets up the encrypted volume as name under the
+// directory specified by Dir(), using the raw volume key instead of a
+// passphrase. If name is nil the key is only checked against the
+// device header and nothing is activated.
+func (d *Device) ActivateByVolumeKey(name *string, key []byte, flags ActivateFlags) error {
+	return d.activateByVolumeKey(name, key, d.activateFlags(flags))
+}
+
+// VolumeKey returns the volume key of the device, unlocking it with
+// pass from the given keyslot (or any keyslot if slot is AnySlot).
+//
+// The key is copied out of the library's secure memory into a new
+// slice owned by the caller, who should Wipe it once done with it.
+func (d *Device) VolumeKey(slot int, pass []byte) ([]byte, error) {
+	size := C.size_t(C.crypt_get_volume_key_size(d.cd))
+	if size == 0 {
+		return nil, ErrNoVolumeKey
+	}
+	buf := (*C.char)(C.crypt_safe_alloc(size))
+	if buf == nil {
+		return nil, newError(-int(syscall.ENOMEM), nil)
+	}
+	defer C.crypt_safe_free(unsafe.Pointer(buf))
+
+	_, err := d.volumeKeyGet(slot, buf, &size, pass)
+	if err != nil {
+		return nil, err
+	}
+	return C.GoBytes(unsafe.Pointer(buf), C.int(size)), nil
+}
+
+// Wipe overwrites key material with zeros.
+func Wipe(key []byte) {
+	for i := range key {
+		key[i] = 0
+	}
 }
 
 // Deactivate removes the active device-mapper mapping from the
@@ -131,6 +290,51 @@ func (d *Device) Deactivate(name string) error {
 	return d.deactivate(name)
 }
 
+// Suspend blocks all I/O to the active mapping name and wipes its
+// volume key from kernel memory. The mapping stays in place until it
+// is resumed with one of the Resume methods.
+func (d *Device) Suspend(name string) error {
+	return d.suspend(name)
+}
+
+// Resume unblocks the mapping name suspended by Suspend, unlocking it
+// with pass.
+func (d *Device) Resume(name string, pass []byte) error {
+	_, err := d.resumeByPassphrase(name, AnySlot, pass)
+	return err
+}
+
+// ResumeByKeyfile is like Resume, but unlocks the mapping with the
+// contents of keyfile in the same way as ActivateByKeyfile.
+func (d *Device) ResumeByKeyfile(name, keyfile string, size, offset uint64) error {
+	_, err := d.resumeByKeyfileDeviceOffset(
+		name,
+		AnySlot,
+		keyfile,
+		size,
+		offset,
+	)
+	return err
+}
+
+// ResumeByVolumeKey is like Resume, but unlocks the mapping with the
+// raw volume key.
+func (d *Device) ResumeByVolumeKey(name string, key []byte) error {
+	return d.resumeByVolumeKey(name, key)
+}
+
+// SectorSize is the unit, in bytes, of sizes and offsets passed to
+// and from device-mapper.
+const SectorSize = 512
+
+// Resize changes the size of the active mapping name to newSize
+// sectors (of SectorSize bytes each). A newSize of 0 grows the
+// mapping to fill the underlying device, e.g. after the device itself
+// has been enlarged.
+func (d *Device) Resize(name string, newSize uint64) error {
+	return d.resize(name, newSize)
+}
+
 // Name returns the name of the underlying device. This is the same as
 // the argument passed to NewDevice.
 func (d *Device) Name() string {
@@ -148,7 +352,8 @@ func (d *Device) SetUuid(uuid string) error {
 }
 
 // Params returns a Params object with the cryptographic parameters
-// used by the device.
+// used by the device. The integrity protection of the device is
+// reported by IntegrityInfo.
 func (d *Device) Params() (pp Params) {
 	pp.Cipher = C.GoString(C.crypt_get_cipher(d.cd))
 	pp.Mode = C.GoString(C.crypt_get_cipher_mode(d.cd))
@@ -156,12 +361,44 @@ func (d *Device) Params() (pp Params) {
 	return
 }
 
+// dumpLines returns the output of the library's dump of the device
+// split into lines. Some metadata is only reported there.
+func (d *Device) dumpLines() ([]string, error) {
+	messages, err := d.dump()
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.Join(messages, ""), "\n"), nil
+}
+
+// dumpField splits a "key: value" line of the library's dump,
+// trimming the padding around both.
+func dumpField(l string) (key, value string, ok bool) {
+	kv := strings.SplitN(l, ":", 2)
+	if len(kv) != 2 {
+		return "", "", false
+	}
+	return strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]), true
+}
+
 // SetIterationTime sets how log it should take to construct a key
 // from a password. The default is about 1 second.
 func (d *Device) SetIterationTime(t time.Duration) {
 	C.crypt_set_iteration_time(d.cd, C.uint64_t(t.Seconds() * 1000))
 }
 
+// SetPbkdf sets the key derivation function used for new keyslots.
+func (d *Device) SetPbkdf(p PbkdfParams) error {
+	s, free := p.c()
+	defer free()
+	return d.setPbkdfType(s)
+}
+
+// Pbkdf returns the key derivation function used for new keyslots.
+func (d *Device) Pbkdf() PbkdfParams {
+	return newPbkdfParams(C.crypt_get_pbkdf_type(d.cd))
+}
+
 // SetDataDevice specifies a device to use in detached header mode.
 func (d *Device) SetDataDevice(name string) error {
 	return d.setDataDevice(name)
@@ -178,6 +415,35 @@ func (d *Device) AddKey(pass []byte, newpass []byte) error {
 	return err
 }
 
+// AddKeyToSlot is like AddKey, but puts newpass into keyslot slot,
+// which must be free. It returns the keyslot holding newpass, which is
+// useful when slot is AnySlot.
+func (d *Device) AddKeyToSlot(slot int, pass []byte, newpass []byte) (int, error) {
+	if _, ok := <-firstInitStatus; ok {
+		defer close(firstInitStatus)
+	}
+
+	return d.keyslotAddByPassphrase(slot, pass, newpass)
+}
+
+// ChangeKey replaces the password oldpass stored in keyslot slot (or
+// any keyslot if slot is AnySlot) with newpass. It returns the keyslot
+// now holding newpass.
+//
+// Like `cryptsetup luksChangeKey`, newpass is written to a free
+// keyslot when there is one and the keyslot of oldpass is only
+// removed afterwards. When no keyslot is free, the keyslot is
+// rewritten in place and a crash or write failure in between leaves
+// neither password working, losing the device if it was the only
+// keyslot. Keep a HeaderBackup when that can happen.
+func (d *Device) ChangeKey(slot int, oldpass, newpass []byte) (newSlot int, err error) {
+	if _, ok := <-firstInitStatus; ok {
+		defer close(firstInitStatus)
+	}
+
+	return d.keyslotChangeByPassphrase(slot, AnySlot, oldpass, newpass)
+}
+
 // DelKey removes the password specified by pass from the device,
 // effectively making it impossible to decrypt the device with that
 // password any more. Note that this is not guaranteed to work on SSDs
@@ -192,3 +458,20 @@ func (d *Device) DelKey(pass []byte) (err error) {
 	err = d.keyslotDestroy(i)
 	return
 }
+
+// DestroySlot removes keyslot slot from the device without needing
+// its password. It refuses to destroy the last active keyslot, which
+// would make the device impossible to unlock, use ForceDestroySlot
+// for that.
+func (d *Device) DestroySlot(slot int) error {
+	if C.crypt_keyslot_status(d.cd, C.int(slot)) == C.CRYPT_SLOT_ACTIVE_LAST {
+		return ErrLastKeyslot
+	}
+	return d.keyslotDestroy(slot)
+}
+
+// ForceDestroySlot is like DestroySlot, but also destroys the last
+// active keyslot.
+func (d *Device) ForceDestroySlot(slot int) error {
+	return d.keyslotDestroy(slot)
+}
@@ -5,10 +5,15 @@ import (
 	"os"
 	"testing"
 	"time"
+
+	"github.com/kcolford/go-cryptsetup/keyring"
 )
 
 const luksSize = 1049600
 
+// LUKS2 reserves 16MiB for its metadata by default
+const luks2Size = 32 << 20
+
 func freeme(d *Device, f *os.File) {
 	d.Close()
 	err := os.Remove(f.Name())
@@ -206,3 +211,783 @@ func TestDevice_Format_error(t *testing.T) {
 	}
 	t.Fail()
 }
+
+func TestDevice_Luks2(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, Luks2Params{
+		Label:     "go-cryptsetup",
+		Subsystem: "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("addkey", func(t *testing.T) {
+		err := d.AddKey(mypassword, []byte(passwords[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("load-luks2", func(t *testing.T) {
+		d, err := NewDevice(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Close()
+
+		err = d.Load(Luks2Params{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("load-luks1", func(t *testing.T) {
+		d, err := NewDevice(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Close()
+
+		err = d.Load(LuksParams{})
+		if err != nil {
+			return
+		}
+		t.Fail()
+	})
+}
+
+func TestDevice_Format_luks2Pbkdf(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, Luks2Params{
+		Pbkdf: &PbkdfParams{
+			Type: "pbkdf2",
+			Time: 10 * time.Millisecond,
+		},
+		SectorSize: 4096,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDevice_Pbkdf(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, Luks2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := PbkdfParams{
+		Type:            KdfArgon2id,
+		Iterations:      4,
+		MaxMemoryKb:     32,
+		ParallelThreads: 1,
+		Flags:           PbkdfNoBenchmark,
+	}
+	err = d.SetPbkdf(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := d.Pbkdf()
+	if got.Type != p.Type || got.Iterations != p.Iterations || got.MaxMemoryKb != p.MaxMemoryKb {
+		t.Fatalf("got %+v, want %+v", got, p)
+	}
+
+	err = d.AddKey(mypassword, []byte(passwords[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDevice_BenchmarkKdf(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	for _, kdf := range []string{KdfPbkdf2, KdfArgon2i, KdfArgon2id} {
+		t.Run(kdf, func(t *testing.T) {
+			p, err := d.BenchmarkKdf(PbkdfParams{
+				Type:        kdf,
+				Time:        10 * time.Millisecond,
+				MaxMemoryKb: 1024,
+			}, mypassword, []byte("0123456789abcdef"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Iterations == 0 {
+				t.Fail()
+			}
+		})
+	}
+}
+
+func TestDevice_Keyslots(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []CryptParameter{LuksParams{}, Luks2Params{}} {
+		d, f, err := makeDeviceSize(luks2Size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer freeme(d, f)
+
+		err = d.Format(mypassword, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ks, err := d.Keyslots()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ks[0].Status != KeyslotActiveLast || ks[1].Status != KeyslotInactive {
+			t.Fatalf("unexpected keyslots %+v", ks[:2])
+		}
+
+		err = d.AddKey(mypassword, []byte(passwords[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ks, err = d.Keyslots()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range ks[:2] {
+			if k.Status != KeyslotActive || k.Cipher == "" || k.Pbkdf.Type == "" {
+				t.Fatalf("unexpected keyslot %+v", k)
+			}
+		}
+	}
+}
+
+func makeKeyfile(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "go-cryptsetup_keyfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	_, err = f.WriteString(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func TestDevice_Keyfile(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	// the first keyfile holds mypassword after some padding
+	keyfile := makeKeyfile(t, "padding"+string(mypassword)+"trailer")
+	defer os.Remove(keyfile)
+	size, offset := uint64(len(mypassword)), uint64(len("padding"))
+	newKeyfile := makeKeyfile(t, passwords[0])
+	defer os.Remove(newKeyfile)
+	changedKeyfile := makeKeyfile(t, passwords[1])
+	defer os.Remove(changedKeyfile)
+
+	err = d.Format(mypassword, LuksParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.AddKeyByKeyfile(keyfile, size, offset, newKeyfile, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.ChangeKeyByKeyfile(newKeyfile, 0, 0, changedKeyfile, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the old key must be gone and the new one in place
+	err = d.AddKey([]byte(passwords[0]), []byte(passwords[2]))
+	if err == nil {
+		t.Fatal("changed key still unlocks the device")
+	}
+	err = d.AddKey([]byte(passwords[1]), []byte(passwords[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("activate", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("only root can activate a device")
+		}
+		name := "example_keyfile_device"
+		err := d.ActivateByKeyfile(&name, keyfile, size, offset, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Deactivate(name)
+	})
+}
+
+func TestDevice_ActivateByVolumeKey_error(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, LuksParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.ActivateByVolumeKey(nil, make([]byte, 256/8), 0)
+	if err != nil {
+		return
+	}
+	t.Fail()
+}
+
+func TestDevice_VolumeKey(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	err = d.FormatVolumeKey(mypassword, key, LuksParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := d.VolumeKey(AnySlot, mypassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Wipe(got)
+	if string(got) != string(key) {
+		t.Fatalf("got volume key %x, want %x", got, key)
+	}
+
+	err = d.ActivateByVolumeKey(nil, got, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = d.VolumeKey(AnySlot, []byte("wrong password"))
+	if err == nil {
+		t.Fatal("wrong password unlocked the volume key")
+	}
+}
+
+func TestDevice_VolumeKey_error(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	// nothing is loaded
+	_, err = d.VolumeKey(AnySlot, mypassword)
+	if err != ErrNoVolumeKey {
+		t.Fatalf("got %v, want %v", err, ErrNoVolumeKey)
+	}
+}
+
+func TestDevice_HeaderBackup(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []CryptParameter{LuksParams{}, Luks2Params{}} {
+		d, f, err := makeDeviceSize(luks2Size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer freeme(d, f)
+
+		err = d.Format(mypassword, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dir, err := ioutil.TempDir("", "go-cryptsetup_backup")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		backup := dir + "/header"
+		err = d.HeaderBackup(backup, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// destroy the on-disk header, including the secondary
+		// LUKS2 header
+		_, err = f.WriteAt(make([]byte, 1<<20), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err = NewDevice(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Close()
+		err = d.Load(nil)
+		if err == nil {
+			t.Fatal("loaded a destroyed header")
+		}
+
+		err = d.HeaderRestore(backup, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d, err = NewDevice(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Close()
+		err = d.Load(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = d.VolumeKey(AnySlot, mypassword)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDevice_Status(t *testing.T) {
+	t.Parallel()
+	if os.Geteuid() != 0 {
+		t.Skip("only root can activate a device")
+	}
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, LuksParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "example_status_device"
+	s, err := d.Status(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Status != StatusInactive {
+		t.Fatalf("got status %v, want %v", s.Status, StatusInactive)
+	}
+
+	err = d.Activate(name, mypassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Deactivate(name)
+
+	s, err = d.Status(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Status != StatusActive || s.Size == 0 || s.ReadOnly {
+		t.Fatalf("unexpected status %+v", s)
+	}
+}
+
+func TestDevice_Resize(t *testing.T) {
+	t.Parallel()
+	if os.Geteuid() != 0 {
+		t.Skip("only root can activate a device")
+	}
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, LuksParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "example_resize_device"
+	err = d.Activate(name, mypassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Deactivate(name)
+
+	before, err := d.Status(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.Resize(name, before.Size/2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.Status(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Size != before.Size/2 {
+		t.Fatalf("got size %d, want %d", s.Size, before.Size/2)
+	}
+
+	// grow the image and the mapping along with it
+	err = f.Truncate(2 * luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Resize(name, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err = d.Status(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := before.Size + luks2Size/SectorSize
+	if s.Size != want {
+		t.Fatalf("got size %d, want %d", s.Size, want)
+	}
+}
+
+func TestDevice_Suspend(t *testing.T) {
+	t.Parallel()
+	if os.Geteuid() != 0 {
+		t.Skip("only root can activate a device")
+	}
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	err = d.FormatVolumeKey(mypassword, key, LuksParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "example_suspend_device"
+	err = d.Activate(name, mypassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Deactivate(name)
+
+	resumes := map[string]func() error{
+		"passphrase": func() error { return d.Resume(name, mypassword) },
+		"volume-key": func() error { return d.ResumeByVolumeKey(name, key) },
+	}
+	for how, resume := range resumes {
+		err = d.Suspend(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := d.Status(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !s.Suspended {
+			t.Fatalf("%s: device not suspended", how)
+		}
+		err = resume()
+		if err != nil {
+			t.Fatalf("%s: %v", how, err)
+		}
+	}
+}
+
+func TestDevice_ChangeKey(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []CryptParameter{LuksParams{}, Luks2Params{}} {
+		d, f, err := makeDeviceSize(luks2Size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer freeme(d, f)
+
+		err = d.Format(mypassword, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		slot, err := d.ChangeKey(0, mypassword, []byte(passwords[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = d.VolumeKey(AnySlot, mypassword)
+		if err == nil {
+			t.Fatal("old password still unlocks the device")
+		}
+		_, err = d.VolumeKey(slot, []byte(passwords[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyslots, err := d.Keyslots()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range keyslots {
+			if k.InUse() != (k.Slot == slot) {
+				t.Fatalf("unexpected keyslot %+v", k)
+			}
+		}
+	}
+}
+
+func TestDevice_ChangeKey_full(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []CryptParameter{LuksParams{}, Luks2Params{}} {
+		d, f, err := makeDeviceSize(luks2Size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer freeme(d, f)
+
+		err = d.Format(mypassword, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = d.SetPbkdf(*fastPbkdf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// fill every other keyslot
+		keyslots, err := d.Keyslots()
+		if err != nil {
+			t.Fatal(err)
+		}
+		other := []byte(passwords[1])
+		for i := 1; i < len(keyslots); i++ {
+			_, err = d.AddKeyToSlot(i, mypassword, other)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// without a free keyslot the keyslot is rewritten in place
+		slot, err := d.ChangeKey(AnySlot, mypassword, []byte(passwords[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slot != 0 {
+			t.Fatalf("new password in keyslot %d, want 0", slot)
+		}
+		_, err = d.VolumeKey(0, mypassword)
+		if err == nil {
+			t.Fatal("old password still unlocks the device")
+		}
+		_, err = d.VolumeKey(0, []byte(passwords[0]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyslots, err = d.Keyslots()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, k := range keyslots {
+			if !k.InUse() {
+				t.Fatalf("unexpected keyslot %+v", k)
+			}
+		}
+	}
+}
+
+func TestDevice_Slots(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, LuksParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slot, err := d.AddKeyToSlot(5, mypassword, []byte(passwords[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot != 5 {
+		t.Fatalf("key added to keyslot %d", slot)
+	}
+	_, err = d.AddKeyToSlot(5, mypassword, []byte(passwords[1]))
+	if err == nil {
+		t.Fatal("overwrote a keyslot in use")
+	}
+
+	_, err = d.VolumeKey(5, mypassword)
+	if err == nil {
+		t.Fatal("unlocked keyslot 5 with the wrong password")
+	}
+
+	err = d.DestroySlot(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.DestroySlot(5)
+	if err != ErrLastKeyslot {
+		t.Fatalf("got %v, want %v", err, ErrLastKeyslot)
+	}
+	err = d.ForceDestroySlot(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("activate", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("only root can activate a device")
+		}
+		d, f, err := makeDevice()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer freeme(d, f)
+		err = d.Format(mypassword, LuksParams{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = d.ActivateWithSlot("example_slot_device", 1, mypassword, 0)
+		if err == nil {
+			t.Fatal("activated an empty keyslot")
+		}
+		slot, err := d.ActivateWithSlot("example_slot_device", 0, mypassword, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Deactivate("example_slot_device")
+		if slot != 0 {
+			t.Fatalf("activated keyslot %d", slot)
+		}
+	})
+}
+
+func TestDevice_Keyring(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, Luks2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	desc := "go-cryptsetup:test-passphrase"
+	key, err := keyring.Add("user", desc, mypassword, keyring.ProcessKeyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer keyring.Unlink(key, keyring.ProcessKeyring)
+
+	// only check the passphrase
+	slot, err := d.ActivateByKeyring(nil, desc, AnySlot, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot != 0 {
+		t.Fatalf("unlocked keyslot %d", slot)
+	}
+
+	t.Run("activate", func(t *testing.T) {
+		if os.Geteuid() != 0 {
+			t.Skip("only root can activate a device")
+		}
+		err := d.SetVolumeKeyKeyring(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		name := "example_keyring_device"
+		_, err = d.ActivateByKeyring(&name, desc, AnySlot, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Deactivate(name)
+	})
+}
+
+func TestDevice_Pbkdf_defaults(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	// only the type is needed, the time and costs default to the
+	// library's
+	err = d.Format(mypassword, Luks2Params{
+		Pbkdf: &PbkdfParams{Type: KdfArgon2id},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Pbkdf(); got.Type != KdfArgon2id {
+		t.Fatalf("unexpected pbkdf %+v", got)
+	}
+
+	err = d.SetPbkdf(PbkdfParams{Type: KdfPbkdf2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.AddKey(mypassword, []byte(passwords[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.Pbkdf(); got.Type != KdfPbkdf2 || got.Hash != DefaultHash {
+		t.Fatalf("unexpected pbkdf %+v", got)
+	}
+}
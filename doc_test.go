@@ -26,9 +26,15 @@ func ExampleDevice_BenchmarkKdf() {
 	}
 	defer d.Close()
 
-	iter, err := d.BenchmarkKdf("", []byte("my password"), []byte("secure salt"))
+	p, err := d.BenchmarkKdf(PbkdfParams{}, []byte("my password"), []byte("secure salt"))
 	if err != nil {
 		panic(err)
 	}
-	fmt.Printf("Kdf iterations per second: %d\n", iter)
+	fmt.Printf("Kdf iterations per second: %d\n", p.Iterations)
+
+	p, err = d.BenchmarkKdf(PbkdfParams{Type: KdfArgon2id}, []byte("my password"), []byte("secure salt"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Argon2id: %d iterations, %d KiB, %d threads\n", p.Iterations, p.MaxMemoryKb, p.ParallelThreads)
 }
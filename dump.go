@@ -0,0 +1,66 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+import "C"
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/kcolford/go-cryptsetup/luks"
+)
+
+// Dump returns the metadata of the loaded LUKS header. LUKS1 headers
+// are returned in the same shape as LUKS2 metadata, as they would be
+// converted to LUKS2.
+func (d *Device) Dump() (m luks.Metadata, err error) {
+	if C.GoString(C.crypt_get_type(d.cd)) == C.CRYPT_LUKS1 {
+		h, err := d.luks1Header()
+		if err != nil {
+			return m, err
+		}
+		return h.Metadata(), nil
+	}
+
+	js, err := d.DumpJSON()
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal([]byte(js), &m)
+	return
+}
+
+// DumpJSON returns the JSON metadata of the loaded LUKS2 header. For
+// LUKS1 headers the metadata returned by Dump is encoded instead.
+func (d *Device) DumpJSON() (string, error) {
+	if C.GoString(C.crypt_get_type(d.cd)) == C.CRYPT_LUKS1 {
+		h, err := d.luks1Header()
+		if err != nil {
+			return "", err
+		}
+		js, err := json.Marshal(h.Metadata())
+		return string(js), err
+	}
+
+	var s *C.char
+	err := d.dumpJson(&s, 0)
+	if err != nil {
+		return "", err
+	}
+	return C.GoString(s), nil
+}
+
+// luks1Header reads the LUKS1 header straight from the detached
+// header or the device, the library has no JSON form of it.
+func (d *Device) luks1Header() (*luks.V1Header, error) {
+	path := C.GoString(C.crypt_get_metadata_device_name(d.cd))
+	if path == "" {
+		path = d.Name()
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return luks.ReadV1(f)
+}
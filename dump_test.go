@@ -0,0 +1,83 @@
+package cryptsetup
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kcolford/go-cryptsetup/luks"
+)
+
+func TestDevice_Dump(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []CryptParameter{LuksParams{}, Luks2Params{}} {
+		p := p
+		t.Run(reflect.TypeOf(p).Name(), func(t *testing.T) {
+			t.Parallel()
+
+			d, f, err := makeDeviceSize(luks2Size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer freeme(d, f)
+
+			err = d.Format(mypassword, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = d.AddKey(mypassword, []byte("another password"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			m, err := d.Dump()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(m.Keyslots) != 2 || m.Keyslots[0].Type != "luks2" || m.Keyslots[1].KeySize != 32 {
+				t.Fatalf("unexpected keyslots %+v", m.Keyslots)
+			}
+			s := m.Segments[0]
+			if len(m.Segments) != 1 || s.Encryption != "aes-xts-plain64" || !s.Dynamic || s.Offset == 0 {
+				t.Fatalf("unexpected segments %+v", m.Segments)
+			}
+			if !reflect.DeepEqual(m.Digests[0].Keyslots, luks.IDs{0, 1}) {
+				t.Fatalf("unexpected digests %+v", m.Digests)
+			}
+
+			js, err := d.DumpJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var jm luks.Metadata
+			err = json.Unmarshal([]byte(js), &jm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(jm, m) {
+				t.Fatalf("JSON %s does not match %+v", js, m)
+			}
+		})
+	}
+}
+
+func TestDevice_Dump_error(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	// nothing has been loaded
+	_, err = d.DumpJSON()
+	if err == nil {
+		t.Fatal("dumped the JSON metadata of an empty device")
+	}
+	_, err = d.Dump()
+	if err == nil {
+		t.Fatal("dumped the metadata of an empty device")
+	}
+}
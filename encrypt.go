@@ -0,0 +1,166 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+import "C"
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// DefaultDataShift is the amount of space (in 512 byte sectors)
+// reserved for the LUKS2 header by EncryptInPlace. It is the size of
+// the default LUKS2 metadata and keyslots area.
+const DefaultDataShift = 16 << 20 / SectorSize
+
+// EncryptOptions control how EncryptInPlace encrypts a device.
+type EncryptOptions struct {
+	// Luks2 are the parameters of the new LUKS2 header.
+	Luks2 Luks2Params
+
+	// Header is the path of a detached header to create, which
+	// must not exist yet. If it is empty, the header is stored at
+	// the start of the device and the data is shifted to make
+	// room for it.
+	Header string
+
+	// DataShift is the amount of space (in 512 byte sectors) made
+	// for the header, or 0 for DefaultDataShift. When the header
+	// is stored on the device, the data is shifted by DataShift
+	// sectors and the last 2*DataShift sectors of the device must
+	// not be in use, as they are overwritten.
+	DataShift uint64
+
+	// Resilience is the resilience mode used with a detached
+	// header, ResilienceDatashift is used otherwise.
+	Resilience string
+
+	// Progress, if not nil, is called as the encryption
+	// proceeds.
+	Progress ReencryptProgress
+}
+
+// EncryptInPlace encrypts the existing unencrypted data on the device
+// at path with a new LUKS2 header, unlocked by pass.
+//
+// If Progress interrupts the encryption, the device is left in a
+// consistent state and the encryption can be finished with
+// ReencryptResume and Reencrypt.
+func EncryptInPlace(path string, pass []byte, opts EncryptOptions) error {
+	if opts.DataShift == 0 {
+		opts.DataShift = DefaultDataShift
+	}
+	p := ReencryptParams{
+		Mode:       ReencryptEncrypt,
+		Direction:  ReencryptForward,
+		Resilience: opts.Resilience,
+		Luks2:      opts.Luks2,
+		Flags:      ReencryptInitializeOnly,
+	}
+	p.Luks2.DataDevice = &path
+
+	var f *os.File
+	var err error
+	if opts.Header != "" {
+		f, err = os.OpenFile(opts.Header, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if err != nil {
+			return err
+		}
+	} else {
+		// the header is built in a temporary file first and
+		// only written to the device once the data at its
+		// start has been moved out of the way
+		f, err = ioutil.TempFile("", "go-cryptsetup_header")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+
+		p.Direction = ReencryptBackward
+		p.Resilience = ResilienceDatashift
+		p.DataShift = opts.DataShift
+		p.Flags |= ReencryptMoveFirstSegment
+	}
+	header := f.Name()
+	// the library does not grow a detached header, make room for
+	// the metadata and keyslots
+	err = f.Truncate(int64(opts.DataShift * SectorSize))
+	f.Close()
+	if err != nil {
+		return err
+	}
+
+	d, err := NewDevice(header)
+	if err != nil {
+		return err
+	}
+	defer func() { d.Close() }()
+	if opts.Header == "" {
+		err = d.setDataOffset(opts.DataShift)
+		if err != nil {
+			return err
+		}
+	}
+	err = d.Format(pass, p.Luks2)
+	if err != nil {
+		return err
+	}
+
+	// the freshly formatted header has just the one keyslot
+	err = d.ReencryptInit(nil, pass, 0, p)
+	if err != nil {
+		return err
+	}
+
+	if opts.Header == "" {
+		err = moveFirstSegment(path, int64(opts.DataShift*SectorSize))
+		if err != nil {
+			return err
+		}
+		d.Close()
+		d, err = NewDevice(path)
+		if err != nil {
+			return err
+		}
+		err = d.HeaderRestore(header, Luks2Params{})
+		if err != nil {
+			return err
+		}
+		err = d.Load(nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = d.ReencryptResume(nil, pass, 0)
+	if err != nil {
+		return err
+	}
+	return d.Reencrypt(opts.Progress)
+}
+
+// moveFirstSegment copies the first size bytes of the device at path
+// to its end, which is where the reencryption expects to find the
+// data that the header is written over.
+func moveFirstSegment(path string, size int64) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	buf := make([]byte, size)
+	_, err = f.ReadAt(buf, 0)
+	if err != nil {
+		return err
+	}
+	_, err = f.WriteAt(buf, end-size)
+	if err != nil {
+		return err
+	}
+	return f.Sync()
+}
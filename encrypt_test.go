@@ -0,0 +1,151 @@
+package cryptsetup
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// makePlaintext creates a device image of size bytes starting with
+// used bytes of a known pattern, followed by zeros.
+func makePlaintext(t *testing.T, size, used int64) (string, []byte) {
+	f, err := ioutil.TempFile("", "go-cryptsetup_plaintext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	pattern := make([]byte, used)
+	for i := range pattern {
+		pattern[i] = byte(i) ^ byte(i>>12) ^ byte(i>>20)*31
+	}
+	_, err = f.Write(pattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Truncate(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Name(), pattern
+}
+
+// decryptInPlace decrypts the device at path, opening the header at
+// header.
+func decryptInPlace(t *testing.T, header, path string) {
+	d, err := NewDevice(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	err = d.Load(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header != path {
+		err = d.SetDataDevice(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = d.ReencryptInit(nil, mypassword, AnySlot, ReencryptParams{
+		Mode:       ReencryptDecrypt,
+		Resilience: ResilienceChecksum,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Reencrypt(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+var fastPbkdf = &PbkdfParams{
+	Type:       KdfPbkdf2,
+	Iterations: 1000,
+	Flags:      PbkdfNoBenchmark,
+}
+
+func TestEncryptInPlace(t *testing.T) {
+	t.Parallel()
+
+	const used = 8 << 20
+	const shift = 4 << 20 / SectorSize
+	path, pattern := makePlaintext(t, 2*shift*SectorSize+used, used)
+	defer os.Remove(path)
+
+	calls := 0
+	err := EncryptInPlace(path, mypassword, EncryptOptions{
+		Luks2:     Luks2Params{Pbkdf: fastPbkdf},
+		DataShift: shift,
+		Progress: func(size, offset uint64) bool {
+			calls++
+			return true
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls == 0 {
+		t.Fatal("progress was never called")
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, pattern[:4096]) {
+		t.Fatal("plaintext is still on the device")
+	}
+
+	decryptInPlace(t, path, path)
+	b, err = ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := shift * SectorSize
+	if !bytes.Equal(b[offset:offset+used], pattern) {
+		t.Fatal("decrypted data does not match")
+	}
+}
+
+func TestEncryptInPlace_detached(t *testing.T) {
+	t.Parallel()
+
+	const size = 8 << 20
+	path, pattern := makePlaintext(t, size, size)
+	defer os.Remove(path)
+	dir, err := ioutil.TempDir("", "go-cryptsetup_header")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	header := filepath.Join(dir, "header")
+
+	err = EncryptInPlace(path, mypassword, EncryptOptions{
+		Luks2:  Luks2Params{Pbkdf: fastPbkdf},
+		Header: header,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(b, pattern) {
+		t.Fatal("plaintext is still on the device")
+	}
+
+	decryptInPlace(t, header, path)
+	b, err = ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, pattern) {
+		t.Fatal("decrypted data does not match")
+	}
+}
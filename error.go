@@ -1,11 +1,25 @@
 package cryptsetup
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"syscall"
 )
 
+// ErrLastKeyslot is returned when refusing to destroy the last active
+// keyslot of a device.
+var ErrLastKeyslot = errors.New("refusing to destroy the last active keyslot")
+
+// ErrKeyslotsLost is returned when refusing to reencrypt a device
+// whose other active keyslots would be removed without a
+// replacement.
+var ErrKeyslotsLost = errors.New("refusing to remove the keyslots of other passphrases")
+
+// ErrNoVolumeKey is returned when asking for the volume key of a
+// device that has none, e.g. because no header is loaded.
+var ErrNoVolumeKey = errors.New("the device has no volume key")
+
 // CryptError is an error produced by libcryptsetup.
 type CryptError struct {
 	Messages []string
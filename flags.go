@@ -0,0 +1,94 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+import "C"
+import (
+	"fmt"
+	"strings"
+)
+
+// ActivateFlags control how a device-mapper mapping is set up.
+type ActivateFlags uint32
+
+const (
+	ActivateReadOnly                 ActivateFlags = C.CRYPT_ACTIVATE_READONLY
+	ActivateNoUuid                   ActivateFlags = C.CRYPT_ACTIVATE_NO_UUID
+	ActivateShared                   ActivateFlags = C.CRYPT_ACTIVATE_SHARED
+	ActivateAllowDiscards            ActivateFlags = C.CRYPT_ACTIVATE_ALLOW_DISCARDS
+	ActivatePrivate                  ActivateFlags = C.CRYPT_ACTIVATE_PRIVATE
+	ActivateCorrupted                ActivateFlags = C.CRYPT_ACTIVATE_CORRUPTED
+	ActivateSameCpuCrypt             ActivateFlags = C.CRYPT_ACTIVATE_SAME_CPU_CRYPT
+	ActivateSubmitFromCryptCpus      ActivateFlags = C.CRYPT_ACTIVATE_SUBMIT_FROM_CRYPT_CPUS
+	ActivateIgnoreCorruption         ActivateFlags = C.CRYPT_ACTIVATE_IGNORE_CORRUPTION
+	ActivateRestartOnCorruption      ActivateFlags = C.CRYPT_ACTIVATE_RESTART_ON_CORRUPTION
+	ActivateIgnoreZeroBlocks         ActivateFlags = C.CRYPT_ACTIVATE_IGNORE_ZERO_BLOCKS
+	ActivateKeyringKey               ActivateFlags = C.CRYPT_ACTIVATE_KEYRING_KEY
+	ActivateNoJournal                ActivateFlags = C.CRYPT_ACTIVATE_NO_JOURNAL
+	ActivateRecovery                 ActivateFlags = C.CRYPT_ACTIVATE_RECOVERY
+	ActivateIgnorePersistent         ActivateFlags = C.CRYPT_ACTIVATE_IGNORE_PERSISTENT
+	ActivateCheckAtMostOnce          ActivateFlags = C.CRYPT_ACTIVATE_CHECK_AT_MOST_ONCE
+	ActivateAllowUnboundKey          ActivateFlags = C.CRYPT_ACTIVATE_ALLOW_UNBOUND_KEY
+	ActivateRecalculate              ActivateFlags = C.CRYPT_ACTIVATE_RECALCULATE
+	ActivateRefresh                  ActivateFlags = C.CRYPT_ACTIVATE_REFRESH
+	ActivateSerializeMemoryHardPbkdf ActivateFlags = C.CRYPT_ACTIVATE_SERIALIZE_MEMORY_HARD_PBKDF
+	ActivateNoJournalBitmap          ActivateFlags = C.CRYPT_ACTIVATE_NO_JOURNAL_BITMAP
+	ActivateSuspended                ActivateFlags = C.CRYPT_ACTIVATE_SUSPENDED
+	ActivateIvLargeSectors           ActivateFlags = C.CRYPT_ACTIVATE_IV_LARGE_SECTORS
+	ActivatePanicOnCorruption        ActivateFlags = C.CRYPT_ACTIVATE_PANIC_ON_CORRUPTION
+	ActivateNoReadWorkqueue          ActivateFlags = C.CRYPT_ACTIVATE_NO_READ_WORKQUEUE
+	ActivateNoWriteWorkqueue         ActivateFlags = C.CRYPT_ACTIVATE_NO_WRITE_WORKQUEUE
+	ActivateRecalculateReset         ActivateFlags = C.CRYPT_ACTIVATE_RECALCULATE_RESET
+)
+
+var activateFlagNames = []struct {
+	flag ActivateFlags
+	name string
+}{
+	{ActivateReadOnly, "read-only"},
+	{ActivateNoUuid, "no-uuid"},
+	{ActivateShared, "shared"},
+	{ActivateAllowDiscards, "allow-discards"},
+	{ActivatePrivate, "private"},
+	{ActivateCorrupted, "corrupted"},
+	{ActivateSameCpuCrypt, "same-cpu-crypt"},
+	{ActivateSubmitFromCryptCpus, "submit-from-crypt-cpus"},
+	{ActivateIgnoreCorruption, "ignore-corruption"},
+	{ActivateRestartOnCorruption, "restart-on-corruption"},
+	{ActivateIgnoreZeroBlocks, "ignore-zero-blocks"},
+	{ActivateKeyringKey, "keyring-key"},
+	{ActivateNoJournal, "no-journal"},
+	{ActivateRecovery, "recovery"},
+	{ActivateIgnorePersistent, "ignore-persistent"},
+	{ActivateCheckAtMostOnce, "check-at-most-once"},
+	{ActivateAllowUnboundKey, "allow-unbound-key"},
+	{ActivateRecalculate, "recalculate"},
+	{ActivateRefresh, "refresh"},
+	{ActivateSerializeMemoryHardPbkdf, "serialize-memory-hard-pbkdf"},
+	{ActivateNoJournalBitmap, "no-journal-bitmap"},
+	{ActivateSuspended, "suspended"},
+	{ActivateIvLargeSectors, "iv-large-sectors"},
+	{ActivatePanicOnCorruption, "panic-on-corruption"},
+	{ActivateNoReadWorkqueue, "no-read-workqueue"},
+	{ActivateNoWriteWorkqueue, "no-write-workqueue"},
+	{ActivateRecalculateReset, "recalculate-reset"},
+}
+
+// String returns the names of the set flags separated by "|", or
+// "none" if no flag is set. Unknown flags are printed in hex.
+func (f ActivateFlags) String() string {
+	if f == 0 {
+		return "none"
+	}
+	var names []string
+	for _, n := range activateFlagNames {
+		if f&n.flag != 0 {
+			names = append(names, n.name)
+			f &^= n.flag
+		}
+	}
+	if f != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(f)))
+	}
+	return strings.Join(names, "|")
+}
@@ -0,0 +1,22 @@
+package cryptsetup
+
+import (
+	"testing"
+)
+
+func TestActivateFlags_String(t *testing.T) {
+	tests := []struct {
+		flags ActivateFlags
+		want  string
+	}{
+		{0, "none"},
+		{ActivateReadOnly, "read-only"},
+		{ActivateAllowDiscards | ActivateNoReadWorkqueue, "allow-discards|no-read-workqueue"},
+		{ActivateShared | 1<<31, "shared|0x80000000"},
+	}
+	for _, tst := range tests {
+		if got := tst.flags.String(); got != tst.want {
+			t.Errorf("%#x: got %q, want %q", uint32(tst.flags), got, tst.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+import "C"
+import (
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// Fvault2Params is used for opening FileVault2 devices. These can
+// only be loaded, there is nothing to configure. Once loaded, the
+// device is activated with Activate, ActivateWithFlags or
+// ActivateWithSlot using the passphrase of the volume. The mapping
+// is always read-only, ActivateReadOnly is added to the flags.
+type Fvault2Params struct{}
+
+func (p Fvault2Params) CMode() (t string, pp Params, out unsafe.Pointer, free func()) {
+	return C.CRYPT_FVAULT2, pp, nil, func() {}
+}
+
+// Fvault2Info describes the metadata of a FileVault2 device loaded
+// with Fvault2Params. Offsets and sizes are in bytes.
+type Fvault2Info struct {
+	PhysicalVolumeUuid  string
+	FamilyUuid          string // UUID of the logical volume family
+	LogicalVolumeOffset uint64
+	LogicalVolumeSize   uint64
+	Cipher              string // e.g. "aes"
+	Mode                string // e.g. "xts-plain64"
+	PbkdfIterations     uint32 // PBKDF2 iterations protecting the key
+}
+
+// Fvault2Info returns the metadata of the FileVault2 device loaded
+// with Fvault2Params.
+func (d *Device) Fvault2Info() (Fvault2Info, error) {
+	lines, err := d.dumpLines()
+	if err != nil {
+		return Fvault2Info{}, err
+	}
+	return parseFvault2Info(lines), nil
+}
+
+// parseFvault2Info reads the metadata out of the library's dump of a
+// FileVault2 device.
+func parseFvault2Info(lines []string) (info Fvault2Info) {
+	for _, l := range lines {
+		k, v, ok := dumpField(l)
+		if !ok {
+			continue
+		}
+		switch k {
+		case "Physical volume UUID":
+			info.PhysicalVolumeUuid = v
+		case "Family UUID":
+			info.FamilyUuid = v
+		case "Logical volume offset":
+			info.LogicalVolumeOffset, _ = strconv.ParseUint(strings.TrimSuffix(v, " [bytes]"), 10, 64)
+		case "Logical volume size":
+			info.LogicalVolumeSize, _ = strconv.ParseUint(strings.TrimSuffix(v, " [bytes]"), 10, 64)
+		case "Cipher":
+			info.Cipher = v
+		case "Cipher mode":
+			info.Mode = v
+		case "PBKDF2 iterations":
+			n, _ := strconv.ParseUint(v, 10, 32)
+			info.PbkdfIterations = uint32(n)
+		}
+	}
+	return
+}
@@ -12,9 +12,9 @@ import (
 )
 
 {{range .Methods}}
-func (d *Device) {{.GoName}}({{range $k, $v := .DeclParams}}{{if $k}}, {{end}}{{$v.Name}} {{$v.GoType}}{{end}}) ({{with .Return}}out {{.}}, {{end}}err error) {
+func (d *Device) {{.GoName}}({{range $k, $v := .DeclParams}}{{if $k}}, {{end}}{{$v.Name}} {{$v.GoType}}{{end}}) ({{with .Return}}out {{.}}, {{end}}{{if .Output}}messages []string, {{end}}err error) {
 	arglist := (*C.struct_gocrypt_logstack)(nil)
-	{{range .Params}}
+	{{range .GlueParams}}
 	{{if eq "*string" (.GoType)}}
 	var _{{.Name}} *C.char
 	if {{.Value}} != nil {
@@ -28,7 +28,11 @@ func (d *Device) {{.GoName}}({{range $k, $v := .DeclParams}}{{if $k}}, {{end}}{{
 	_{{.Name}} := unsafe.Pointer(nil)
 	if {{.Value}} != nil {
 		_{{.Name}} = C.CBytes({{.Value}})
-		defer C.free(_{{.Name}})
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_{{.Name}}), len({{.Value}})))
+			C.free(_{{.Name}})
+		}()
 	} else {
 		{{if .CanNil}}
 		// this value can be nil
@@ -55,12 +59,13 @@ func (d *Device) {{.GoName}}({{range $k, $v := .DeclParams}}{{if $k}}, {{end}}{{
 	ival := C.{{$.Ns}}_{{.Name}}(
 		&arglist,
 		{{if .SetContext}}&{{end}}d.cd,
-		{{range .Params}}
+		{{range .GlueParams}}
 		_{{.Name}},
 		{{end}}
 	)
 	
-	err = newError(int(ival), logMessages(arglist))
+	{{if .Output}}messages = logMessages(arglist)
+	err = newError(int(ival), messages){{else}}err = newError(int(ival), logMessages(arglist)){{end}}
 	{{with .Return}}out = ({{.}})(ival){{end}}
 	return
 }
@@ -7,7 +7,7 @@
 #include <libcryptsetup.h>
 
 {{range .Methods}}
-int {{$.Ns}}_{{.Name}}(struct gocrypt_logstack **, struct crypt_device *{{if .SetContext}}*{{end}}{{range .Params}}, {{.Type}}{{end}});
+int {{$.Ns}}_{{.Name}}(struct gocrypt_logstack **, struct crypt_device *{{if .SetContext}}*{{end}}{{range .GlueParams}}, {{.Type}}{{end}});
 {{end}}
 
 #endif /* {{$.HeaderGuard}} */
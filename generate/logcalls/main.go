@@ -24,6 +24,10 @@ type MethodParam struct {
 	// pass this Go code as the argument to the C function
 	ForceArg string
 
+	// pass this C code as the argument to the library function,
+	// the parameter is then left out of the glue function
+	ForceCArg string
+
 	// this parameter is unsafe
 	Unsafe bool
 
@@ -45,6 +49,10 @@ type Method struct {
 	// result too. this is the type of that meaningful result
 	Return string
 
+	// the function reports its result through the log, return
+	// the messages even when it succeeds
+	Output bool
+
 	SetContext    bool
 	CanNilContext bool
 }
@@ -109,14 +117,46 @@ var data = Data{
 				Unsafe: true, CanNil: true},
 		}},
 
+		{Name: "crypt_header_backup", Params: []MethodParam{
+			{Type: "const char *", Name: "requested_type", CanNil: true},
+			{Type: "const char *", Name: "backup_file"},
+		}},
+		{Name: "crypt_header_restore", Params: []MethodParam{
+			{Type: "const char *", Name: "requested_type", CanNil: true},
+			{Type: "const char *", Name: "backup_file"},
+		}},
+
 		// misc
 		{Name: "crypt_get_rng_type", Return: "int"},
+		{Name: "crypt_dump", Output: true},
+		{Name: "crypt_dump_json", Params: []MethodParam{
+			{Type: "const char **", Name: "json"},
+			{Type: "uint32_t", Name: "flags"},
+		}},
+		{Name: "crypt_set_pbkdf_type", Params: []MethodParam{
+			{Type: "struct crypt_pbkdf_type *", Name: "pbkdf", CanNil: true},
+		}},
 		{Name: "crypt_set_uuid", Params: []MethodParam{
 			{Type: "const char *", Name: "uuid"},
 		}},
 		{Name: "crypt_set_data_device", Params: []MethodParam{
 			{Type: "const char *", Name: "name"},
 		}},
+		{Name: "crypt_set_data_offset", Params: []MethodParam{
+			{Type: "uint64_t", Name: "data_offset"},
+		}},
+		{Name: "crypt_volume_key_keyring", Params: []MethodParam{
+			{Type: "int", Name: "enable"},
+		}},
+
+		{Name: "crypt_volume_key_get", Params: []MethodParam{
+			{Type: "int", Name: "keyslot"},
+			{Type: "char *", Name: "volume_key"},
+			{Type: "size_t *", Name: "volume_key_size"},
+			{Type: "void *", Name: "passphrase", CanNil: true},
+			{Type: "size_t", Name: "passphrase_size",
+				ForceArg: "len(passphrase)"},
+		}, Return: "int"},
 
 		// keyslot managment
 		{Name: "crypt_keyslot_add_by_passphrase", Params: []MethodParam{
@@ -128,10 +168,78 @@ var data = Data{
 			{Type: "size_t", Name: "new_passphrase_size",
 				ForceArg: "len(new_passphrase)"},
 		}, Return: "int"},
+		{Name: "crypt_keyslot_add_by_volume_key", Params: []MethodParam{
+			{Type: "int", Name: "keyslot"},
+			{Type: "void *", Name: "volume_key", CanNil: true},
+			{Type: "size_t", Name: "volume_key_size",
+				ForceArg: "len(volume_key)"},
+			{Type: "void *", Name: "passphrase"},
+			{Type: "size_t", Name: "passphrase_size",
+				ForceArg: "len(passphrase)"},
+		}, Return: "int"},
+		{Name: "crypt_keyslot_add_by_key", Params: []MethodParam{
+			{Type: "int", Name: "keyslot"},
+			{Type: "void *", Name: "volume_key", CanNil: true},
+			{Type: "size_t", Name: "volume_key_size"},
+			{Type: "void *", Name: "passphrase"},
+			{Type: "size_t", Name: "passphrase_size",
+				ForceArg: "len(passphrase)"},
+			{Type: "uint32_t", Name: "flags"},
+		}, Return: "int"},
+		{Name: "crypt_keyslot_add_by_keyfile_device_offset", Params: []MethodParam{
+			{Type: "int", Name: "keyslot"},
+			{Type: "const char *", Name: "keyfile"},
+			{Type: "size_t", Name: "keyfile_size"},
+			{Type: "uint64_t", Name: "keyfile_offset"},
+			{Type: "const char *", Name: "new_keyfile"},
+			{Type: "size_t", Name: "new_keyfile_size"},
+			{Type: "uint64_t", Name: "new_keyfile_offset"},
+		}, Return: "int"},
+		{Name: "crypt_keyslot_change_by_passphrase", Params: []MethodParam{
+			{Type: "int", Name: "keyslot_old"},
+			{Type: "int", Name: "keyslot_new"},
+			{Type: "void *", Name: "passphrase"},
+			{Type: "size_t", Name: "passphrase_size",
+				ForceArg: "len(passphrase)"},
+			{Type: "void *", Name: "new_passphrase"},
+			{Type: "size_t", Name: "new_passphrase_size",
+				ForceArg: "len(new_passphrase)"},
+		}, Return: "int"},
+		{Name: "crypt_keyfile_device_read", Params: []MethodParam{
+			{Type: "const char *", Name: "keyfile"},
+			{Type: "char **", Name: "key"},
+			{Type: "size_t *", Name: "key_size_read"},
+			{Type: "uint64_t", Name: "keyfile_offset"},
+			{Type: "size_t", Name: "key_size"},
+			{Type: "uint32_t", Name: "flags"},
+		}},
 		{Name: "crypt_keyslot_destroy", Params: []MethodParam{
 			{Type: "int", Name: "keyslot"},
 		}},
 
+		{Name: "crypt_keyslot_get_pbkdf", Params: []MethodParam{
+			{Type: "int", Name: "keyslot"},
+			{Type: "struct crypt_pbkdf_type *", Name: "pbkdf"},
+		}},
+
+		// tokens
+		{Name: "crypt_token_json_get", Params: []MethodParam{
+			{Type: "int", Name: "token"},
+			{Type: "const char **", Name: "json"},
+		}, Return: "int"},
+		{Name: "crypt_token_json_set", Params: []MethodParam{
+			{Type: "int", Name: "token"},
+			{Type: "const char *", Name: "json", CanNil: true},
+		}, Return: "int"},
+		{Name: "crypt_token_assign_keyslot", Params: []MethodParam{
+			{Type: "int", Name: "token"},
+			{Type: "int", Name: "keyslot"},
+		}, Return: "int"},
+		{Name: "crypt_token_unassign_keyslot", Params: []MethodParam{
+			{Type: "int", Name: "token"},
+			{Type: "int", Name: "keyslot"},
+		}, Return: "int"},
+
 		// device activation
 		{Name: "crypt_activate_by_passphrase", Params: []MethodParam{
 			{Type: "const char *", Name: "name", CanNil: true},
@@ -141,6 +249,48 @@ var data = Data{
 				ForceArg: "len(passphrase)"},
 			{Type: "uint32_t", Name: "flags"},
 		}, Return: "int"},
+		{Name: "crypt_activate_by_keyfile_device_offset", Params: []MethodParam{
+			{Type: "const char *", Name: "name", CanNil: true},
+			{Type: "int", Name: "keyslot"},
+			{Type: "const char *", Name: "keyfile"},
+			{Type: "size_t", Name: "keyfile_size"},
+			{Type: "uint64_t", Name: "keyfile_offset"},
+			{Type: "uint32_t", Name: "flags"},
+		}, Return: "int"},
+		{Name: "crypt_activate_by_volume_key", Params: []MethodParam{
+			{Type: "const char *", Name: "name", CanNil: true},
+			{Type: "void *", Name: "volume_key", CanNil: true},
+			{Type: "size_t", Name: "volume_key_size",
+				ForceArg: "len(volume_key)"},
+			{Type: "uint32_t", Name: "flags"},
+		}},
+		{Name: "crypt_activate_by_signed_key", Params: []MethodParam{
+			{Type: "const char *", Name: "name", CanNil: true},
+			{Type: "void *", Name: "volume_key"},
+			{Type: "size_t", Name: "volume_key_size",
+				ForceArg: "len(volume_key)"},
+			{Type: "void *", Name: "signature", CanNil: true},
+			{Type: "size_t", Name: "signature_size",
+				ForceArg: "len(signature)"},
+			{Type: "uint32_t", Name: "flags"},
+		}},
+		{Name: "crypt_activate_by_token_pin", Params: []MethodParam{
+			{Type: "const char *", Name: "name", CanNil: true},
+			{Type: "const char *", Name: "token_type", CanNil: true},
+			{Type: "int", Name: "token"},
+			{Type: "void *", Name: "pin", CanNil: true},
+			{Type: "size_t", Name: "pin_size",
+				ForceArg: "len(pin)"},
+			{Type: "void *", Name: "usrptr",
+				Unsafe: true, CanNil: true},
+			{Type: "uint32_t", Name: "flags"},
+		}, Return: "int"},
+		{Name: "crypt_activate_by_keyring", Params: []MethodParam{
+			{Type: "const char *", Name: "name", CanNil: true},
+			{Type: "const char *", Name: "key_description"},
+			{Type: "int", Name: "keyslot"},
+			{Type: "uint32_t", Name: "flags"},
+		}, Return: "int"},
 		{Name: "crypt_get_active_device", Params: []MethodParam{
 			{Type: "const char *", Name: "name"},
 			{Type: "struct crypt_active_device *", Name: "cad"},
@@ -148,6 +298,52 @@ var data = Data{
 		{Name: "crypt_deactivate", Params: []MethodParam{
 			{Type: "const char *", Name: "name"},
 		}},
+		{Name: "crypt_suspend", Params: []MethodParam{
+			{Type: "const char *", Name: "name"},
+		}},
+		{Name: "crypt_resume_by_passphrase", Params: []MethodParam{
+			{Type: "const char *", Name: "name"},
+			{Type: "int", Name: "keyslot"},
+			{Type: "void *", Name: "passphrase"},
+			{Type: "size_t", Name: "passphrase_size",
+				ForceArg: "len(passphrase)"},
+		}, Return: "int"},
+		{Name: "crypt_resume_by_keyfile_device_offset", Params: []MethodParam{
+			{Type: "const char *", Name: "name"},
+			{Type: "int", Name: "keyslot"},
+			{Type: "const char *", Name: "keyfile"},
+			{Type: "size_t", Name: "keyfile_size"},
+			{Type: "uint64_t", Name: "keyfile_offset"},
+		}, Return: "int"},
+		{Name: "crypt_resume_by_volume_key", Params: []MethodParam{
+			{Type: "const char *", Name: "name"},
+			{Type: "void *", Name: "volume_key"},
+			{Type: "size_t", Name: "volume_key_size",
+				ForceArg: "len(volume_key)"},
+		}},
+		{Name: "crypt_resize", Params: []MethodParam{
+			{Type: "const char *", Name: "name"},
+			{Type: "uint64_t", Name: "new_size"},
+		}},
+
+		// reencryption
+		{Name: "crypt_reencrypt_init_by_passphrase", Params: []MethodParam{
+			{Type: "const char *", Name: "name", CanNil: true},
+			{Type: "void *", Name: "passphrase"},
+			{Type: "size_t", Name: "passphrase_size",
+				ForceArg: "len(passphrase)"},
+			{Type: "int", Name: "keyslot_old"},
+			{Type: "int", Name: "keyslot_new"},
+			{Type: "const char *", Name: "cipher", CanNil: true},
+			{Type: "const char *", Name: "cipher_mode", CanNil: true},
+			{Type: "void *", Name: "params", Unsafe: true},
+		}, Return: "int"},
+		{Name: "crypt_reencrypt_run", Params: []MethodParam{
+			{Type: "void *", Name: "progress",
+				ForceCArg: "gocrypt_reencrypt_progress"},
+			{Type: "void *", Name: "usrptr",
+				Unsafe: true, CanNil: true},
+		}},
 
 		// benchmarking
 		{Name: "crypt_benchmark", Params: []MethodParam{
@@ -159,16 +355,30 @@ var data = Data{
 			{Type: "double *", Name: "encryption_mbs"},
 			{Type: "double *", Name: "decryption_mbs"},
 		}},
-		{Name: "crypt_benchmark_kdf", Params: []MethodParam{
-			{Type: "const char *", Name: "kdf"},
-			{Type: "const char *", Name: "hash"},
+		{Name: "crypt_benchmark_pbkdf", Params: []MethodParam{
+			{Type: "struct crypt_pbkdf_type *", Name: "pbkdf"},
 			{Type: "void *", Name: "password"},
 			{Type: "size_t", Name: "password_size",
 				ForceArg: "len(password)"},
 			{Type: "void *", Name: "salt"},
 			{Type: "size_t", Name: "salt_size",
 				ForceArg: "len(salt)"},
-			{Type: "uint64_t *", Name: "iterations_sec"},
+			{Type: "size_t", Name: "volume_key_size"},
+			{Type: "void *", Name: "progress", ForceCArg: "NULL"},
+			{Type: "void *", Name: "usrptr", ForceCArg: "NULL"},
+		}},
+
+		// wiping
+		{Name: "crypt_wipe", Params: []MethodParam{
+			{Type: "const char *", Name: "dev_path"},
+			// a crypt_wipe_pattern
+			{Type: "int", Name: "pattern"},
+			{Type: "uint64_t", Name: "offset"},
+			{Type: "uint64_t", Name: "length"},
+			{Type: "size_t", Name: "wipe_block_size"},
+			{Type: "uint32_t", Name: "flags"},
+			{Type: "void *", Name: "progress", ForceCArg: "NULL"},
+			{Type: "void *", Name: "usrptr", ForceCArg: "NULL"},
 		}},
 	},
 }
@@ -189,7 +399,7 @@ func (m Method) GoName() string {
 // actually be exposed to the Go code.
 func (m Method) DeclParams() []MethodParam {
 	out := make([]MethodParam, 0, len(m.Params))
-	for _, p := range m.Params {
+	for _, p := range m.GlueParams() {
 		if p.ForceArg == "" {
 			out = append(out, p)
 		}
@@ -197,6 +407,18 @@ func (m Method) DeclParams() []MethodParam {
 	return out
 }
 
+// GlueParams returns a slice of all the MethodParams that will be
+// passed through the C glue function.
+func (m Method) GlueParams() []MethodParam {
+	out := make([]MethodParam, 0, len(m.Params))
+	for _, p := range m.Params {
+		if p.ForceCArg == "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
 // Value returns the value that will be passed to the C glue function
 // from the Go code.
 func (p MethodParam) Value() string {
@@ -206,13 +428,22 @@ func (p MethodParam) Value() string {
 	return p.Name
 }
 
+// CValue returns the value that will be passed to the library
+// function from the C glue function.
+func (p MethodParam) CValue() string {
+	if p.ForceCArg != "" {
+		return p.ForceCArg
+	}
+	return p.Name
+}
+
 // CType returns the Go mapping of the C datatype for a method
 // parameter.
 func (p MethodParam) CType() string {
 	if p.Unsafe || p.Type == "void *" {
 		return "unsafe.Pointer"
 	}
-	s := "C." + p.Type
+	s := "C." + strings.TrimPrefix(p.Type, "const ")
 	for s[len(s)-1] == '*' {
 		s = "*" + s[:len(s)-1]
 	}
@@ -0,0 +1,121 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"time"
+	"unsafe"
+)
+
+// IntegrityParams is the set of parameters used for standalone
+// dm-integrity devices, which detect (but do not prevent) silent
+// corruption of the data without encrypting it.
+//
+// Integrity devices have no keyslots, so Format ignores its key and
+// keyed integrity algorithms take their key from FormatVolumeKey and
+// ActivateIntegrity instead.
+type IntegrityParams struct {
+	JournalSize       uint64        // size of the journal (in bytes) or 0
+	JournalWatermark  uint32        // journal flush watermark (in percent) or 0
+	JournalCommitTime time.Duration // journal commit interval or 0
+	InterleaveSectors uint32        // number of interleaved sectors or 0
+	TagSize           uint32        // integrity tag size (in bytes) per sector or 0
+	SectorSize        uint32        // integrity sector size (in bytes) or 0
+	BufferSectors     uint32        // number of sectors in one buffer or 0
+
+	Integrity        string // integrity algorithm, "crc32c" if ""
+	IntegrityKeySize uint32 // key size (in bytes) for keyed algorithms
+
+	JournalIntegrity    string // journal integrity algorithm or ""
+	JournalIntegrityKey []byte // key for a keyed JournalIntegrity
+	JournalCrypt        string // journal encryption cipher or ""
+	JournalCryptKey     []byte // key for JournalCrypt
+}
+
+func newIntegrityParams(s *C.struct_crypt_params_integrity) IntegrityParams {
+	return IntegrityParams{
+		JournalSize:       uint64(s.journal_size),
+		JournalWatermark:  uint32(s.journal_watermark),
+		JournalCommitTime: time.Duration(s.journal_commit_time) * time.Millisecond,
+		InterleaveSectors: uint32(s.interleave_sectors),
+		TagSize:           uint32(s.tag_size),
+		SectorSize:        uint32(s.sector_size),
+		BufferSectors:     uint32(s.buffer_sectors),
+		Integrity:         C.GoString(s.integrity),
+		IntegrityKeySize:  uint32(s.integrity_key_size),
+		JournalIntegrity:  C.GoString(s.journal_integrity),
+		JournalCrypt:      C.GoString(s.journal_crypt),
+	}
+}
+
+func (p IntegrityParams) CMode() (t string, pp Params, out unsafe.Pointer, free func()) {
+	if p.Integrity == "" {
+		p.Integrity = "crc32c"
+	}
+
+	t = C.CRYPT_INTEGRITY
+	pp.VolumeKeySize = uint64(p.IntegrityKeySize)
+	s := C.struct_crypt_params_integrity{
+		journal_size:               C.uint64_t(p.JournalSize),
+		journal_watermark:          C.uint(p.JournalWatermark),
+		journal_commit_time:        C.uint(p.JournalCommitTime / time.Millisecond),
+		interleave_sectors:         C.uint32_t(p.InterleaveSectors),
+		tag_size:                   C.uint32_t(p.TagSize),
+		sector_size:                C.uint32_t(p.SectorSize),
+		buffer_sectors:             C.uint32_t(p.BufferSectors),
+		integrity:                  C.CString(p.Integrity),
+		integrity_key_size:         C.uint32_t(p.IntegrityKeySize),
+		journal_integrity:          nil,
+		journal_integrity_key:      nil,
+		journal_integrity_key_size: C.uint32_t(len(p.JournalIntegrityKey)),
+		journal_crypt:              nil,
+		journal_crypt_key:          nil,
+		journal_crypt_key_size:     C.uint32_t(len(p.JournalCryptKey)),
+	}
+	if p.JournalIntegrity != "" {
+		s.journal_integrity = C.CString(p.JournalIntegrity)
+	}
+	if p.JournalIntegrityKey != nil {
+		s.journal_integrity_key = (*C.char)(C.CBytes(p.JournalIntegrityKey))
+	}
+	if p.JournalCrypt != "" {
+		s.journal_crypt = C.CString(p.JournalCrypt)
+	}
+	if p.JournalCryptKey != nil {
+		s.journal_crypt_key = (*C.char)(C.CBytes(p.JournalCryptKey))
+	}
+	out = C.malloc(C.sizeof_struct_crypt_params_integrity)
+	*(*C.struct_crypt_params_integrity)(out) = s
+	free = func() {
+		Wipe(unsafe.Slice((*byte)(unsafe.Pointer(s.journal_integrity_key)), len(p.JournalIntegrityKey)))
+		Wipe(unsafe.Slice((*byte)(unsafe.Pointer(s.journal_crypt_key)), len(p.JournalCryptKey)))
+		C.free(out)
+		// C.free is a no-op on nil pointers
+		C.free(unsafe.Pointer(s.integrity))
+		C.free(unsafe.Pointer(s.journal_integrity))
+		C.free(unsafe.Pointer(s.journal_integrity_key))
+		C.free(unsafe.Pointer(s.journal_crypt))
+		C.free(unsafe.Pointer(s.journal_crypt_key))
+	}
+	return
+}
+
+// ActivateIntegrity activates a dm-integrity device loaded with
+// IntegrityParams under the given name. The key is only needed for
+// keyed integrity algorithms and should be nil otherwise.
+func (d *Device) ActivateIntegrity(name string, key []byte, flags ActivateFlags) error {
+	return d.activateByVolumeKey(&name, key, d.activateFlags(flags))
+}
+
+// IntegrityInfo returns the integrity parameters of the device, e.g.
+// of a LUKS2 device formatted with an integrity algorithm, ok is false
+// if it has no integrity protection.
+func (d *Device) IntegrityInfo() (p IntegrityParams, ok bool) {
+	var s C.struct_crypt_params_integrity
+	if C.crypt_get_integrity_info(d.cd, &s) < 0 || s.integrity == nil {
+		return p, false
+	}
+	return newIntegrityParams(&s), true
+}
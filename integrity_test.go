@@ -0,0 +1,91 @@
+package cryptsetup
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDevice_Integrity(t *testing.T) {
+	t.Parallel()
+	if os.Geteuid() != 0 {
+		t.Skip("only root can format an integrity device")
+	}
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	p := IntegrityParams{
+		Integrity:  "crc32c",
+		TagSize:    4,
+		SectorSize: 512,
+	}
+	err = d.Format(nil, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d2, err := NewDevice(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d2.Close()
+	err = d2.Load(IntegrityParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "example_integrity_device"
+	err = d2.ActivateIntegrity(name, nil, ActivateNoJournal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d2.Deactivate(name)
+
+	s, err := d2.Status(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Status != StatusActive || s.Integrity != p.Integrity || s.TagSize != p.TagSize {
+		t.Fatalf("unexpected status %+v", s)
+	}
+}
+
+func TestDevice_Luks2Integrity(t *testing.T) {
+	t.Parallel()
+	if os.Geteuid() != 0 {
+		t.Skip("only root can format an integrity device")
+	}
+
+	for _, p := range []Luks2Params{
+		{
+			Params:    Params{Cipher: "aes", Mode: "gcm-random", VolumeKeySize: 256 / 8},
+			Integrity: "aead",
+			NoWipe:    true,
+		},
+		{
+			Params:    Params{Cipher: "aes", Mode: "xts-random", VolumeKeySize: (512 + 256) / 8},
+			Integrity: "hmac(sha256)",
+		},
+	} {
+		p := p
+		t.Run(p.Integrity, func(t *testing.T) {
+			d, f, err := makeDeviceSize(luks2Size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer freeme(d, f)
+
+			err = d.Format(mypassword, p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ip, ok := d.IntegrityInfo()
+			if !ok || ip.Integrity == "" || ip.TagSize == 0 {
+				t.Fatalf("unexpected integrity info %+v", ip)
+			}
+		})
+	}
+}
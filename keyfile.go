@@ -0,0 +1,89 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// ActivateByKeyfile sets up the encrypted volume as name under the
+// directory specified by Dir(), unlocking it with the contents of
+// keyfile. At most size bytes of the keyfile are read, starting
+// offset bytes into it, and a size of 0 reads the keyfile to the end.
+// If name is nil the keyfile is only checked and nothing is activated.
+func (d *Device) ActivateByKeyfile(name *string, keyfile string, size, offset uint64, flags ActivateFlags) error {
+	_, err := d.activateByKeyfileDeviceOffset(
+		name,
+		AnySlot,
+		keyfile,
+		size,
+		offset,
+		d.activateFlags(flags),
+	)
+	return err
+}
+
+// AddKeyByKeyfile adds the contents of newKeyfile as a new key to
+// the block device, first unlocking it with the contents of keyfile.
+// The size and offset arguments select the part of each keyfile that
+// is read, like for ActivateByKeyfile.
+func (d *Device) AddKeyByKeyfile(keyfile string, size, offset uint64, newKeyfile string, newSize, newOffset uint64) error {
+	if _, ok := <-firstInitStatus; ok {
+		defer close(firstInitStatus)
+	}
+
+	_, err := d.keyslotAddByKeyfileDeviceOffset(
+		AnySlot,
+		keyfile,
+		size,
+		offset,
+		newKeyfile,
+		newSize,
+		newOffset,
+	)
+	return err
+}
+
+// ChangeKeyByKeyfile replaces the key stored in keyfile with the
+// contents of newKeyfile. The new key is put into a free keyslot if
+// there is one, otherwise it overwrites the old key's keyslot (see
+// ChangeKey). The size and offset arguments select the part of each
+// keyfile that is read, like for ActivateByKeyfile.
+func (d *Device) ChangeKeyByKeyfile(keyfile string, size, offset uint64, newKeyfile string, newSize, newOffset uint64) error {
+	if _, ok := <-firstInitStatus; ok {
+		defer close(firstInitStatus)
+	}
+
+	pass, err := d.readKeyfile(keyfile, size, offset)
+	if err != nil {
+		return err
+	}
+	defer Wipe(pass)
+	newpass, err := d.readKeyfile(newKeyfile, newSize, newOffset)
+	if err != nil {
+		return err
+	}
+	defer Wipe(newpass)
+	_, err = d.keyslotChangeByPassphrase(
+		AnySlot,
+		AnySlot,
+		pass,
+		newpass,
+	)
+	return err
+}
+
+// readKeyfile reads a keyfile the same way the library does when
+// unlocking a device with it. The caller should Wipe the key once
+// done with it.
+func (d *Device) readKeyfile(keyfile string, size, offset uint64) ([]byte, error) {
+	var key *C.char
+	var n C.size_t
+	err := d.keyfileDeviceRead(keyfile, &key, &n, offset, size, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer C.crypt_safe_free(unsafe.Pointer(key))
+	return C.GoBytes(unsafe.Pointer(key), C.int(n)), nil
+}
@@ -0,0 +1,99 @@
+// Package keyring is a minimal interface to the Linux kernel keyring,
+// enough to hand passphrases to libcryptsetup without going through
+// outside tools such as keyctl(1).
+package keyring
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// Serial identifies a key or keyring in the kernel.
+type Serial int32
+
+// Special keyrings that can be used in place of a Serial.
+const (
+	ThreadKeyring      Serial = -1
+	ProcessKeyring     Serial = -2
+	SessionKeyring     Serial = -3
+	UserKeyring        Serial = -4
+	UserSessionKeyring Serial = -5
+)
+
+// keyctl(2) operations
+const (
+	keyctlUnlink = 9
+	keyctlSearch = 10
+)
+
+// Add adds a key of type keyType (usually "user") with the given
+// description and payload to ring, or updates the payload of an
+// existing key with the same type and description.
+func Add(keyType, description string, payload []byte, ring Serial) (Serial, error) {
+	t, err := syscall.BytePtrFromString(keyType)
+	if err != nil {
+		return 0, err
+	}
+	d, err := syscall.BytePtrFromString(description)
+	if err != nil {
+		return 0, err
+	}
+	var p unsafe.Pointer
+	if len(payload) > 0 {
+		p = unsafe.Pointer(&payload[0])
+	}
+	id, _, errno := syscall.Syscall6(
+		syscall.SYS_ADD_KEY,
+		uintptr(unsafe.Pointer(t)),
+		uintptr(unsafe.Pointer(d)),
+		uintptr(p),
+		uintptr(len(payload)),
+		uintptr(ring),
+		0,
+	)
+	if errno != 0 {
+		return 0, errno
+	}
+	return Serial(id), nil
+}
+
+// Search looks for a key of type keyType with the given description
+// in ring and the keyrings linked from it.
+func Search(ring Serial, keyType, description string) (Serial, error) {
+	t, err := syscall.BytePtrFromString(keyType)
+	if err != nil {
+		return 0, err
+	}
+	d, err := syscall.BytePtrFromString(description)
+	if err != nil {
+		return 0, err
+	}
+	id, _, errno := syscall.Syscall6(
+		syscall.SYS_KEYCTL,
+		keyctlSearch,
+		uintptr(ring),
+		uintptr(unsafe.Pointer(t)),
+		uintptr(unsafe.Pointer(d)),
+		0,
+		0,
+	)
+	if errno != 0 {
+		return 0, errno
+	}
+	return Serial(id), nil
+}
+
+// Unlink removes key from ring. The kernel destroys the key once it
+// is no longer linked from any keyring.
+func Unlink(key, ring Serial) error {
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_KEYCTL,
+		keyctlUnlink,
+		uintptr(key),
+		uintptr(ring),
+	)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
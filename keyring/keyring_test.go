@@ -0,0 +1,31 @@
+package keyring
+
+import (
+	"syscall"
+	"testing"
+)
+
+func TestKeyring(t *testing.T) {
+	desc := "go-cryptsetup:keyring-test"
+	id, err := Add("user", desc, []byte("secret"), ProcessKeyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := Search(ProcessKeyring, "user", desc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if found != id {
+		t.Fatalf("found key %d, want %d", found, id)
+	}
+
+	err = Unlink(id, ProcessKeyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Search(ProcessKeyring, "user", desc)
+	if err != syscall.ENOKEY {
+		t.Fatalf("got %v, want %v", err, syscall.ENOKEY)
+	}
+}
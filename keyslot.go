@@ -0,0 +1,108 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+import "C"
+
+// AnySlot lets the library pick the keyslot to use, i.e. the first
+// free keyslot when adding a key or the first keyslot that unlocks
+// when activating.
+const AnySlot = C.CRYPT_ANY_SLOT
+
+// KeyslotStatus is the state of a keyslot in the device header.
+type KeyslotStatus int
+
+const (
+	KeyslotInvalid    KeyslotStatus = C.CRYPT_SLOT_INVALID
+	KeyslotInactive   KeyslotStatus = C.CRYPT_SLOT_INACTIVE
+	KeyslotActive     KeyslotStatus = C.CRYPT_SLOT_ACTIVE
+	KeyslotActiveLast KeyslotStatus = C.CRYPT_SLOT_ACTIVE_LAST // the only active keyslot
+	KeyslotUnbound    KeyslotStatus = C.CRYPT_SLOT_UNBOUND     // not bound to any segment
+)
+
+func (s KeyslotStatus) String() string {
+	switch s {
+	case KeyslotInactive:
+		return "inactive"
+	case KeyslotActive:
+		return "active"
+	case KeyslotActiveLast:
+		return "active-last"
+	case KeyslotUnbound:
+		return "unbound"
+	}
+	return "invalid"
+}
+
+// KeyslotPriority decides the order in which keyslots are tried when
+// unlocking a device.
+type KeyslotPriority int
+
+const (
+	KeyslotPriorityInvalid KeyslotPriority = C.CRYPT_SLOT_PRIORITY_INVALID
+	KeyslotPriorityIgnore  KeyslotPriority = C.CRYPT_SLOT_PRIORITY_IGNORE // only used when named explicitly
+	KeyslotPriorityNormal  KeyslotPriority = C.CRYPT_SLOT_PRIORITY_NORMAL
+	KeyslotPriorityPrefer  KeyslotPriority = C.CRYPT_SLOT_PRIORITY_PREFER // tried before normal keyslots
+)
+
+func (p KeyslotPriority) String() string {
+	switch p {
+	case KeyslotPriorityIgnore:
+		return "ignore"
+	case KeyslotPriorityNormal:
+		return "normal"
+	case KeyslotPriorityPrefer:
+		return "prefer"
+	}
+	return "invalid"
+}
+
+// Keyslot describes a single keyslot of a device.
+type Keyslot struct {
+	Slot     int
+	Status   KeyslotStatus
+	Priority KeyslotPriority
+	Pbkdf    PbkdfParams // only set for slots in use
+	Cipher   string      // keyslot encryption, only set for slots in use
+	KeySize  uint64      // keyslot key size (in bytes)
+}
+
+// InUse reports whether the keyslot holds a key.
+func (k Keyslot) InUse() bool {
+	return k.Status == KeyslotActive ||
+		k.Status == KeyslotActiveLast ||
+		k.Status == KeyslotUnbound
+}
+
+// Keyslots returns the state of every keyslot of the loaded device
+// header. This does not require knowing any of the passphrases.
+func (d *Device) Keyslots() ([]Keyslot, error) {
+	max := int(C.crypt_keyslot_max(C.crypt_get_type(d.cd)))
+	if max < 0 {
+		return nil, newError(max, nil)
+	}
+
+	out := make([]Keyslot, max)
+	for i := range out {
+		k := &out[i]
+		k.Slot = i
+		k.Status = KeyslotStatus(C.crypt_keyslot_status(d.cd, C.int(i)))
+		k.Priority = KeyslotPriority(C.crypt_keyslot_get_priority(d.cd, C.int(i)))
+		if !k.InUse() {
+			continue
+		}
+
+		var pbkdf C.struct_crypt_pbkdf_type
+		err := d.keyslotGetPbkdf(i, &pbkdf)
+		if err != nil {
+			return nil, err
+		}
+		k.Pbkdf = newPbkdfParams(&pbkdf)
+
+		var size C.size_t
+		cipher := C.crypt_keyslot_get_encryption(d.cd, C.int(i), &size)
+		k.Cipher = C.GoString(cipher)
+		k.KeySize = uint64(size)
+	}
+	return out, nil
+}
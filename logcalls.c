@@ -2,6 +2,7 @@
 
 #include "logcalls.h"
 #include "log.h"
+#include "reencrypt.h"
 #include <libcryptsetup.h>
 
 
@@ -35,6 +36,26 @@ int gocrypt_crypt_load(struct gocrypt_logstack **ls, struct crypt_device *cd, co
   return out;
 }
 
+int gocrypt_crypt_header_backup(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * requested_type, const char * backup_file) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_header_backup(cd, requested_type, backup_file);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_header_restore(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * requested_type, const char * backup_file) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_header_restore(cd, requested_type, backup_file);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
 int gocrypt_crypt_get_rng_type(struct gocrypt_logstack **ls, struct crypt_device *cd) {
   int out;
   if (cd)
@@ -45,6 +66,36 @@ int gocrypt_crypt_get_rng_type(struct gocrypt_logstack **ls, struct crypt_device
   return out;
 }
 
+int gocrypt_crypt_dump(struct gocrypt_logstack **ls, struct crypt_device *cd) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_dump(cd);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_dump_json(struct gocrypt_logstack **ls, struct crypt_device *cd, const char ** json, uint32_t flags) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_dump_json(cd, json, flags);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_set_pbkdf_type(struct gocrypt_logstack **ls, struct crypt_device *cd, struct crypt_pbkdf_type * pbkdf) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_set_pbkdf_type(cd, pbkdf);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
 int gocrypt_crypt_set_uuid(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * uuid) {
   int out;
   if (cd)
@@ -65,6 +116,36 @@ int gocrypt_crypt_set_data_device(struct gocrypt_logstack **ls, struct crypt_dev
   return out;
 }
 
+int gocrypt_crypt_set_data_offset(struct gocrypt_logstack **ls, struct crypt_device *cd, uint64_t data_offset) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_set_data_offset(cd, data_offset);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_volume_key_keyring(struct gocrypt_logstack **ls, struct crypt_device *cd, int enable) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_volume_key_keyring(cd, enable);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_volume_key_get(struct gocrypt_logstack **ls, struct crypt_device *cd, int keyslot, char * volume_key, size_t * volume_key_size, void * passphrase, size_t passphrase_size) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_volume_key_get(cd, keyslot, volume_key, volume_key_size, passphrase, passphrase_size);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
 int gocrypt_crypt_keyslot_add_by_passphrase(struct gocrypt_logstack **ls, struct crypt_device *cd, int keyslot, void * passphrase, size_t passphrase_size, void * new_passphrase, size_t new_passphrase_size) {
   int out;
   if (cd)
@@ -75,6 +156,56 @@ int gocrypt_crypt_keyslot_add_by_passphrase(struct gocrypt_logstack **ls, struct
   return out;
 }
 
+int gocrypt_crypt_keyslot_add_by_volume_key(struct gocrypt_logstack **ls, struct crypt_device *cd, int keyslot, void * volume_key, size_t volume_key_size, void * passphrase, size_t passphrase_size) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_keyslot_add_by_volume_key(cd, keyslot, volume_key, volume_key_size, passphrase, passphrase_size);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_keyslot_add_by_key(struct gocrypt_logstack **ls, struct crypt_device *cd, int keyslot, void * volume_key, size_t volume_key_size, void * passphrase, size_t passphrase_size, uint32_t flags) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_keyslot_add_by_key(cd, keyslot, volume_key, volume_key_size, passphrase, passphrase_size, flags);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_keyslot_add_by_keyfile_device_offset(struct gocrypt_logstack **ls, struct crypt_device *cd, int keyslot, const char * keyfile, size_t keyfile_size, uint64_t keyfile_offset, const char * new_keyfile, size_t new_keyfile_size, uint64_t new_keyfile_offset) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_keyslot_add_by_keyfile_device_offset(cd, keyslot, keyfile, keyfile_size, keyfile_offset, new_keyfile, new_keyfile_size, new_keyfile_offset);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_keyslot_change_by_passphrase(struct gocrypt_logstack **ls, struct crypt_device *cd, int keyslot_old, int keyslot_new, void * passphrase, size_t passphrase_size, void * new_passphrase, size_t new_passphrase_size) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_keyslot_change_by_passphrase(cd, keyslot_old, keyslot_new, passphrase, passphrase_size, new_passphrase, new_passphrase_size);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_keyfile_device_read(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * keyfile, char ** key, size_t * key_size_read, uint64_t keyfile_offset, size_t key_size, uint32_t flags) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_keyfile_device_read(cd, keyfile, key, key_size_read, keyfile_offset, key_size, flags);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
 int gocrypt_crypt_keyslot_destroy(struct gocrypt_logstack **ls, struct crypt_device *cd, int keyslot) {
   int out;
   if (cd)
@@ -85,6 +216,56 @@ int gocrypt_crypt_keyslot_destroy(struct gocrypt_logstack **ls, struct crypt_dev
   return out;
 }
 
+int gocrypt_crypt_keyslot_get_pbkdf(struct gocrypt_logstack **ls, struct crypt_device *cd, int keyslot, struct crypt_pbkdf_type * pbkdf) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_keyslot_get_pbkdf(cd, keyslot, pbkdf);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_token_json_get(struct gocrypt_logstack **ls, struct crypt_device *cd, int token, const char ** json) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_token_json_get(cd, token, json);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_token_json_set(struct gocrypt_logstack **ls, struct crypt_device *cd, int token, const char * json) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_token_json_set(cd, token, json);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_token_assign_keyslot(struct gocrypt_logstack **ls, struct crypt_device *cd, int token, int keyslot) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_token_assign_keyslot(cd, token, keyslot);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_token_unassign_keyslot(struct gocrypt_logstack **ls, struct crypt_device *cd, int token, int keyslot) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_token_unassign_keyslot(cd, token, keyslot);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
 int gocrypt_crypt_activate_by_passphrase(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, int keyslot, void * passphrase, size_t passphrase_size, uint32_t flags) {
   int out;
   if (cd)
@@ -95,6 +276,56 @@ int gocrypt_crypt_activate_by_passphrase(struct gocrypt_logstack **ls, struct cr
   return out;
 }
 
+int gocrypt_crypt_activate_by_keyfile_device_offset(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, int keyslot, const char * keyfile, size_t keyfile_size, uint64_t keyfile_offset, uint32_t flags) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_activate_by_keyfile_device_offset(cd, name, keyslot, keyfile, keyfile_size, keyfile_offset, flags);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_activate_by_volume_key(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, void * volume_key, size_t volume_key_size, uint32_t flags) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_activate_by_volume_key(cd, name, volume_key, volume_key_size, flags);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_activate_by_signed_key(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, void * volume_key, size_t volume_key_size, void * signature, size_t signature_size, uint32_t flags) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_activate_by_signed_key(cd, name, volume_key, volume_key_size, signature, signature_size, flags);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_activate_by_token_pin(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, const char * token_type, int token, void * pin, size_t pin_size, void * usrptr, uint32_t flags) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_activate_by_token_pin(cd, name, token_type, token, pin, pin_size, usrptr, flags);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_activate_by_keyring(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, const char * key_description, int keyslot, uint32_t flags) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_activate_by_keyring(cd, name, key_description, keyslot, flags);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
 int gocrypt_crypt_get_active_device(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, struct crypt_active_device * cad) {
   int out;
   if (cd)
@@ -115,6 +346,76 @@ int gocrypt_crypt_deactivate(struct gocrypt_logstack **ls, struct crypt_device *
   return out;
 }
 
+int gocrypt_crypt_suspend(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_suspend(cd, name);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_resume_by_passphrase(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, int keyslot, void * passphrase, size_t passphrase_size) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_resume_by_passphrase(cd, name, keyslot, passphrase, passphrase_size);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_resume_by_keyfile_device_offset(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, int keyslot, const char * keyfile, size_t keyfile_size, uint64_t keyfile_offset) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_resume_by_keyfile_device_offset(cd, name, keyslot, keyfile, keyfile_size, keyfile_offset);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_resume_by_volume_key(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, void * volume_key, size_t volume_key_size) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_resume_by_volume_key(cd, name, volume_key, volume_key_size);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_resize(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, uint64_t new_size) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_resize(cd, name, new_size);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_reencrypt_init_by_passphrase(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * name, void * passphrase, size_t passphrase_size, int keyslot_old, int keyslot_new, const char * cipher, const char * cipher_mode, void * params) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_reencrypt_init_by_passphrase(cd, name, passphrase, passphrase_size, keyslot_old, keyslot_new, cipher, cipher_mode, params);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_reencrypt_run(struct gocrypt_logstack **ls, struct crypt_device *cd, void * usrptr) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_reencrypt_run(cd, gocrypt_reencrypt_progress, usrptr);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
 int gocrypt_crypt_benchmark(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * cipher, const char * cipher_mode, size_t volume_key_size, size_t iv_size, size_t buffer_size, double * encryption_mbs, double * decryption_mbs) {
   int out;
   if (cd)
@@ -125,11 +426,21 @@ int gocrypt_crypt_benchmark(struct gocrypt_logstack **ls, struct crypt_device *c
   return out;
 }
 
-int gocrypt_crypt_benchmark_kdf(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * kdf, const char * hash, void * password, size_t password_size, void * salt, size_t salt_size, uint64_t * iterations_sec) {
+int gocrypt_crypt_benchmark_pbkdf(struct gocrypt_logstack **ls, struct crypt_device *cd, struct crypt_pbkdf_type * pbkdf, void * password, size_t password_size, void * salt, size_t salt_size, size_t volume_key_size) {
+  int out;
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log, ls);
+  out = crypt_benchmark_pbkdf(cd, pbkdf, password, password_size, salt, salt_size, volume_key_size, NULL, NULL);
+  if (cd)
+    crypt_set_log_callback(cd, gocrypt_log_default, NULL);
+  return out;
+}
+
+int gocrypt_crypt_wipe(struct gocrypt_logstack **ls, struct crypt_device *cd, const char * dev_path, int pattern, uint64_t offset, uint64_t length, size_t wipe_block_size, uint32_t flags) {
   int out;
   if (cd)
     crypt_set_log_callback(cd, gocrypt_log, ls);
-  out = crypt_benchmark_kdf(cd, kdf, hash, password, password_size, salt, salt_size, iterations_sec);
+  out = crypt_wipe(cd, dev_path, pattern, offset, length, wipe_block_size, flags, NULL, NULL);
   if (cd)
     crypt_set_log_callback(cd, gocrypt_log_default, NULL);
   return out;
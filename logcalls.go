@@ -64,7 +64,11 @@ func (d *Device) format(format string, cipher string, cipher_mode string, uuid *
 	_volume_key := unsafe.Pointer(nil)
 	if volume_key != nil {
 		_volume_key = C.CBytes(volume_key)
-		defer C.free(_volume_key)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_volume_key), len(volume_key)))
+			C.free(_volume_key)
+		}()
 	} else {
 		
 		// this value can be nil
@@ -146,6 +150,70 @@ func (d *Device) load(requested_type *string, params unsafe.Pointer) (err error)
 	return
 }
 
+func (d *Device) headerBackup(requested_type *string, backup_file string) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	var _requested_type *C.char
+	if requested_type != nil {
+		_requested_type = C.CString(*requested_type)
+		defer C.free(unsafe.Pointer(_requested_type))
+	}
+	
+	
+	
+	_backup_file := C.CString(backup_file)
+	defer C.free(unsafe.Pointer(_backup_file))
+	
+	
+	
+	ival := C.gocrypt_crypt_header_backup(
+		&arglist,
+		d.cd,
+		
+		_requested_type,
+		
+		_backup_file,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) headerRestore(requested_type *string, backup_file string) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	var _requested_type *C.char
+	if requested_type != nil {
+		_requested_type = C.CString(*requested_type)
+		defer C.free(unsafe.Pointer(_requested_type))
+	}
+	
+	
+	
+	_backup_file := C.CString(backup_file)
+	defer C.free(unsafe.Pointer(_backup_file))
+	
+	
+	
+	ival := C.gocrypt_crypt_header_restore(
+		&arglist,
+		d.cd,
+		
+		_requested_type,
+		
+		_backup_file,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
 func (d *Device) getRngType() (out int, err error) {
 	arglist := (*C.struct_gocrypt_logstack)(nil)
 	
@@ -157,24 +225,1410 @@ func (d *Device) getRngType() (out int, err error) {
 	)
 	
 	err = newError(int(ival), logMessages(arglist))
-	out = (int)(ival)
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) dump() (messages []string, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	ival := C.gocrypt_crypt_dump(
+		&arglist,
+		d.cd,
+		
+	)
+	
+	messages = logMessages(arglist)
+	err = newError(int(ival), messages)
+	
+	return
+}
+
+func (d *Device) dumpJson(json **C.char, flags uint32) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	
+	if json == nil {
+		panic("nil unexpected")
+	}
+	
+	
+	_json := (**C.char)(json)
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_dump_json(
+		&arglist,
+		d.cd,
+		
+		_json,
+		
+		_flags,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) setPbkdfType(pbkdf *C.struct_crypt_pbkdf_type) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	
+	// this pointer can be nil
+	
+	
+	_pbkdf := (*C.struct_crypt_pbkdf_type)(pbkdf)
+	
+	
+	
+	ival := C.gocrypt_crypt_set_pbkdf_type(
+		&arglist,
+		d.cd,
+		
+		_pbkdf,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) setUuid(uuid string) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	_uuid := C.CString(uuid)
+	defer C.free(unsafe.Pointer(_uuid))
+	
+	
+	
+	ival := C.gocrypt_crypt_set_uuid(
+		&arglist,
+		d.cd,
+		
+		_uuid,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) setDataDevice(name string) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	_name := C.CString(name)
+	defer C.free(unsafe.Pointer(_name))
+	
+	
+	
+	ival := C.gocrypt_crypt_set_data_device(
+		&arglist,
+		d.cd,
+		
+		_name,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) setDataOffset(data_offset uint64) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_data_offset := (C.uint64_t)(data_offset)
+	
+	
+	
+	ival := C.gocrypt_crypt_set_data_offset(
+		&arglist,
+		d.cd,
+		
+		_data_offset,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) volumeKeyKeyring(enable int) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_enable := (C.int)(enable)
+	
+	
+	
+	ival := C.gocrypt_crypt_volume_key_keyring(
+		&arglist,
+		d.cd,
+		
+		_enable,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) volumeKeyGet(keyslot int, volume_key *C.char, volume_key_size *C.size_t, passphrase []byte) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	
+	
+	if volume_key == nil {
+		panic("nil unexpected")
+	}
+	
+	
+	_volume_key := (*C.char)(volume_key)
+	
+	
+	
+	
+	
+	if volume_key_size == nil {
+		panic("nil unexpected")
+	}
+	
+	
+	_volume_key_size := (*C.size_t)(volume_key_size)
+	
+	
+	
+	_passphrase := unsafe.Pointer(nil)
+	if passphrase != nil {
+		_passphrase = C.CBytes(passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_passphrase), len(passphrase)))
+			C.free(_passphrase)
+		}()
+	} else {
+		
+		// this value can be nil
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_passphrase_size := (C.size_t)(len(passphrase))
+	
+	
+	
+	ival := C.gocrypt_crypt_volume_key_get(
+		&arglist,
+		d.cd,
+		
+		_keyslot,
+		
+		_volume_key,
+		
+		_volume_key_size,
+		
+		_passphrase,
+		
+		_passphrase_size,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) keyslotAddByPassphrase(keyslot int, passphrase []byte, new_passphrase []byte) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	_passphrase := unsafe.Pointer(nil)
+	if passphrase != nil {
+		_passphrase = C.CBytes(passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_passphrase), len(passphrase)))
+			C.free(_passphrase)
+		}()
+	} else {
+		
+		// this value can be nil
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_passphrase_size := (C.size_t)(len(passphrase))
+	
+	
+	
+	_new_passphrase := unsafe.Pointer(nil)
+	if new_passphrase != nil {
+		_new_passphrase = C.CBytes(new_passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_new_passphrase), len(new_passphrase)))
+			C.free(_new_passphrase)
+		}()
+	} else {
+		
+		panic("nil unexpected")
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_new_passphrase_size := (C.size_t)(len(new_passphrase))
+	
+	
+	
+	ival := C.gocrypt_crypt_keyslot_add_by_passphrase(
+		&arglist,
+		d.cd,
+		
+		_keyslot,
+		
+		_passphrase,
+		
+		_passphrase_size,
+		
+		_new_passphrase,
+		
+		_new_passphrase_size,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) keyslotAddByVolumeKey(keyslot int, volume_key []byte, passphrase []byte) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	_volume_key := unsafe.Pointer(nil)
+	if volume_key != nil {
+		_volume_key = C.CBytes(volume_key)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_volume_key), len(volume_key)))
+			C.free(_volume_key)
+		}()
+	} else {
+		
+		// this value can be nil
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_volume_key_size := (C.size_t)(len(volume_key))
+	
+	
+	
+	_passphrase := unsafe.Pointer(nil)
+	if passphrase != nil {
+		_passphrase = C.CBytes(passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_passphrase), len(passphrase)))
+			C.free(_passphrase)
+		}()
+	} else {
+		
+		panic("nil unexpected")
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_passphrase_size := (C.size_t)(len(passphrase))
+	
+	
+	
+	ival := C.gocrypt_crypt_keyslot_add_by_volume_key(
+		&arglist,
+		d.cd,
+		
+		_keyslot,
+		
+		_volume_key,
+		
+		_volume_key_size,
+		
+		_passphrase,
+		
+		_passphrase_size,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) keyslotAddByKey(keyslot int, volume_key []byte, volume_key_size uint64, passphrase []byte, flags uint32) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	_volume_key := unsafe.Pointer(nil)
+	if volume_key != nil {
+		_volume_key = C.CBytes(volume_key)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_volume_key), len(volume_key)))
+			C.free(_volume_key)
+		}()
+	} else {
+		
+		// this value can be nil
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_volume_key_size := (C.size_t)(volume_key_size)
+	
+	
+	
+	_passphrase := unsafe.Pointer(nil)
+	if passphrase != nil {
+		_passphrase = C.CBytes(passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_passphrase), len(passphrase)))
+			C.free(_passphrase)
+		}()
+	} else {
+		
+		panic("nil unexpected")
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_passphrase_size := (C.size_t)(len(passphrase))
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_keyslot_add_by_key(
+		&arglist,
+		d.cd,
+		
+		_keyslot,
+		
+		_volume_key,
+		
+		_volume_key_size,
+		
+		_passphrase,
+		
+		_passphrase_size,
+		
+		_flags,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) keyslotAddByKeyfileDeviceOffset(keyslot int, keyfile string, keyfile_size uint64, keyfile_offset uint64, new_keyfile string, new_keyfile_size uint64, new_keyfile_offset uint64) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	_keyfile := C.CString(keyfile)
+	defer C.free(unsafe.Pointer(_keyfile))
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyfile_size := (C.size_t)(keyfile_size)
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyfile_offset := (C.uint64_t)(keyfile_offset)
+	
+	
+	
+	_new_keyfile := C.CString(new_keyfile)
+	defer C.free(unsafe.Pointer(_new_keyfile))
+	
+	
+	
+	
+	// not a pointer
+	
+	_new_keyfile_size := (C.size_t)(new_keyfile_size)
+	
+	
+	
+	
+	// not a pointer
+	
+	_new_keyfile_offset := (C.uint64_t)(new_keyfile_offset)
+	
+	
+	
+	ival := C.gocrypt_crypt_keyslot_add_by_keyfile_device_offset(
+		&arglist,
+		d.cd,
+		
+		_keyslot,
+		
+		_keyfile,
+		
+		_keyfile_size,
+		
+		_keyfile_offset,
+		
+		_new_keyfile,
+		
+		_new_keyfile_size,
+		
+		_new_keyfile_offset,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) keyslotChangeByPassphrase(keyslot_old int, keyslot_new int, passphrase []byte, new_passphrase []byte) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_keyslot_old := (C.int)(keyslot_old)
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyslot_new := (C.int)(keyslot_new)
+	
+	
+	
+	_passphrase := unsafe.Pointer(nil)
+	if passphrase != nil {
+		_passphrase = C.CBytes(passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_passphrase), len(passphrase)))
+			C.free(_passphrase)
+		}()
+	} else {
+		
+		panic("nil unexpected")
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_passphrase_size := (C.size_t)(len(passphrase))
+	
+	
+	
+	_new_passphrase := unsafe.Pointer(nil)
+	if new_passphrase != nil {
+		_new_passphrase = C.CBytes(new_passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_new_passphrase), len(new_passphrase)))
+			C.free(_new_passphrase)
+		}()
+	} else {
+		
+		panic("nil unexpected")
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_new_passphrase_size := (C.size_t)(len(new_passphrase))
+	
+	
+	
+	ival := C.gocrypt_crypt_keyslot_change_by_passphrase(
+		&arglist,
+		d.cd,
+		
+		_keyslot_old,
+		
+		_keyslot_new,
+		
+		_passphrase,
+		
+		_passphrase_size,
+		
+		_new_passphrase,
+		
+		_new_passphrase_size,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) keyfileDeviceRead(keyfile string, key **C.char, key_size_read *C.size_t, keyfile_offset uint64, key_size uint64, flags uint32) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	_keyfile := C.CString(keyfile)
+	defer C.free(unsafe.Pointer(_keyfile))
+	
+	
+	
+	
+	
+	if key == nil {
+		panic("nil unexpected")
+	}
+	
+	
+	_key := (**C.char)(key)
+	
+	
+	
+	
+	
+	if key_size_read == nil {
+		panic("nil unexpected")
+	}
+	
+	
+	_key_size_read := (*C.size_t)(key_size_read)
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyfile_offset := (C.uint64_t)(keyfile_offset)
+	
+	
+	
+	
+	// not a pointer
+	
+	_key_size := (C.size_t)(key_size)
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_keyfile_device_read(
+		&arglist,
+		d.cd,
+		
+		_keyfile,
+		
+		_key,
+		
+		_key_size_read,
+		
+		_keyfile_offset,
+		
+		_key_size,
+		
+		_flags,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) keyslotDestroy(keyslot int) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	ival := C.gocrypt_crypt_keyslot_destroy(
+		&arglist,
+		d.cd,
+		
+		_keyslot,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) keyslotGetPbkdf(keyslot int, pbkdf *C.struct_crypt_pbkdf_type) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	
+	
+	if pbkdf == nil {
+		panic("nil unexpected")
+	}
+	
+	
+	_pbkdf := (*C.struct_crypt_pbkdf_type)(pbkdf)
+	
+	
+	
+	ival := C.gocrypt_crypt_keyslot_get_pbkdf(
+		&arglist,
+		d.cd,
+		
+		_keyslot,
+		
+		_pbkdf,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) tokenJsonGet(token int, json **C.char) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_token := (C.int)(token)
+	
+	
+	
+	
+	
+	if json == nil {
+		panic("nil unexpected")
+	}
+	
+	
+	_json := (**C.char)(json)
+	
+	
+	
+	ival := C.gocrypt_crypt_token_json_get(
+		&arglist,
+		d.cd,
+		
+		_token,
+		
+		_json,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) tokenJsonSet(token int, json *string) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_token := (C.int)(token)
+	
+	
+	
+	var _json *C.char
+	if json != nil {
+		_json = C.CString(*json)
+		defer C.free(unsafe.Pointer(_json))
+	}
+	
+	
+	
+	ival := C.gocrypt_crypt_token_json_set(
+		&arglist,
+		d.cd,
+		
+		_token,
+		
+		_json,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) tokenAssignKeyslot(token int, keyslot int) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_token := (C.int)(token)
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	ival := C.gocrypt_crypt_token_assign_keyslot(
+		&arglist,
+		d.cd,
+		
+		_token,
+		
+		_keyslot,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) tokenUnassignKeyslot(token int, keyslot int) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	
+	// not a pointer
+	
+	_token := (C.int)(token)
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	ival := C.gocrypt_crypt_token_unassign_keyslot(
+		&arglist,
+		d.cd,
+		
+		_token,
+		
+		_keyslot,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) activateByPassphrase(name *string, keyslot int, passphrase []byte, flags uint32) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	var _name *C.char
+	if name != nil {
+		_name = C.CString(*name)
+		defer C.free(unsafe.Pointer(_name))
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	_passphrase := unsafe.Pointer(nil)
+	if passphrase != nil {
+		_passphrase = C.CBytes(passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_passphrase), len(passphrase)))
+			C.free(_passphrase)
+		}()
+	} else {
+		
+		panic("nil unexpected")
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_passphrase_size := (C.size_t)(len(passphrase))
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_activate_by_passphrase(
+		&arglist,
+		d.cd,
+		
+		_name,
+		
+		_keyslot,
+		
+		_passphrase,
+		
+		_passphrase_size,
+		
+		_flags,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) activateByKeyfileDeviceOffset(name *string, keyslot int, keyfile string, keyfile_size uint64, keyfile_offset uint64, flags uint32) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	var _name *C.char
+	if name != nil {
+		_name = C.CString(*name)
+		defer C.free(unsafe.Pointer(_name))
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	_keyfile := C.CString(keyfile)
+	defer C.free(unsafe.Pointer(_keyfile))
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyfile_size := (C.size_t)(keyfile_size)
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyfile_offset := (C.uint64_t)(keyfile_offset)
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_activate_by_keyfile_device_offset(
+		&arglist,
+		d.cd,
+		
+		_name,
+		
+		_keyslot,
+		
+		_keyfile,
+		
+		_keyfile_size,
+		
+		_keyfile_offset,
+		
+		_flags,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) activateByVolumeKey(name *string, volume_key []byte, flags uint32) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	var _name *C.char
+	if name != nil {
+		_name = C.CString(*name)
+		defer C.free(unsafe.Pointer(_name))
+	}
+	
+	
+	
+	_volume_key := unsafe.Pointer(nil)
+	if volume_key != nil {
+		_volume_key = C.CBytes(volume_key)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_volume_key), len(volume_key)))
+			C.free(_volume_key)
+		}()
+	} else {
+		
+		// this value can be nil
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_volume_key_size := (C.size_t)(len(volume_key))
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_activate_by_volume_key(
+		&arglist,
+		d.cd,
+		
+		_name,
+		
+		_volume_key,
+		
+		_volume_key_size,
+		
+		_flags,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) activateBySignedKey(name *string, volume_key []byte, signature []byte, flags uint32) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	var _name *C.char
+	if name != nil {
+		_name = C.CString(*name)
+		defer C.free(unsafe.Pointer(_name))
+	}
+	
+	
+	
+	_volume_key := unsafe.Pointer(nil)
+	if volume_key != nil {
+		_volume_key = C.CBytes(volume_key)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_volume_key), len(volume_key)))
+			C.free(_volume_key)
+		}()
+	} else {
+		
+		panic("nil unexpected")
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_volume_key_size := (C.size_t)(len(volume_key))
+	
+	
+	
+	_signature := unsafe.Pointer(nil)
+	if signature != nil {
+		_signature = C.CBytes(signature)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_signature), len(signature)))
+			C.free(_signature)
+		}()
+	} else {
+		
+		// this value can be nil
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_signature_size := (C.size_t)(len(signature))
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_activate_by_signed_key(
+		&arglist,
+		d.cd,
+		
+		_name,
+		
+		_volume_key,
+		
+		_volume_key_size,
+		
+		_signature,
+		
+		_signature_size,
+		
+		_flags,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) activateByTokenPin(name *string, token_type *string, token int, pin []byte, usrptr unsafe.Pointer, flags uint32) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	var _name *C.char
+	if name != nil {
+		_name = C.CString(*name)
+		defer C.free(unsafe.Pointer(_name))
+	}
+	
+	
+	
+	var _token_type *C.char
+	if token_type != nil {
+		_token_type = C.CString(*token_type)
+		defer C.free(unsafe.Pointer(_token_type))
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_token := (C.int)(token)
+	
+	
+	
+	_pin := unsafe.Pointer(nil)
+	if pin != nil {
+		_pin = C.CBytes(pin)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_pin), len(pin)))
+			C.free(_pin)
+		}()
+	} else {
+		
+		// this value can be nil
+		
+	}
+	
+	
+	
+	
+	// not a pointer
+	
+	_pin_size := (C.size_t)(len(pin))
+	
+	
+	
+	
+	// not a pointer
+	
+	_usrptr := (unsafe.Pointer)(usrptr)
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_activate_by_token_pin(
+		&arglist,
+		d.cd,
+		
+		_name,
+		
+		_token_type,
+		
+		_token,
+		
+		_pin,
+		
+		_pin_size,
+		
+		_usrptr,
+		
+		_flags,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) activateByKeyring(name *string, key_description string, keyslot int, flags uint32) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	var _name *C.char
+	if name != nil {
+		_name = C.CString(*name)
+		defer C.free(unsafe.Pointer(_name))
+	}
+	
+	
+	
+	_key_description := C.CString(key_description)
+	defer C.free(unsafe.Pointer(_key_description))
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyslot := (C.int)(keyslot)
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_activate_by_keyring(
+		&arglist,
+		d.cd,
+		
+		_name,
+		
+		_key_description,
+		
+		_keyslot,
+		
+		_flags,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) getActiveDevice(name string, cad *C.struct_crypt_active_device) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	_name := C.CString(name)
+	defer C.free(unsafe.Pointer(_name))
+	
+	
+	
+	
+	
+	if cad == nil {
+		panic("nil unexpected")
+	}
+	
+	
+	_cad := (*C.struct_crypt_active_device)(cad)
+	
+	
+	
+	ival := C.gocrypt_crypt_get_active_device(
+		&arglist,
+		d.cd,
+		
+		_name,
+		
+		_cad,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
 	return
 }
 
-func (d *Device) setUuid(uuid string) (err error) {
+func (d *Device) deactivate(name string) (err error) {
 	arglist := (*C.struct_gocrypt_logstack)(nil)
 	
 	
-	_uuid := C.CString(uuid)
-	defer C.free(unsafe.Pointer(_uuid))
+	_name := C.CString(name)
+	defer C.free(unsafe.Pointer(_name))
 	
 	
 	
-	ival := C.gocrypt_crypt_set_uuid(
+	ival := C.gocrypt_crypt_deactivate(
 		&arglist,
 		d.cd,
 		
-		_uuid,
+		_name,
 		
 	)
 	
@@ -183,7 +1637,7 @@ func (d *Device) setUuid(uuid string) (err error) {
 	return
 }
 
-func (d *Device) setDataDevice(name string) (err error) {
+func (d *Device) suspend(name string) (err error) {
 	arglist := (*C.struct_gocrypt_logstack)(nil)
 	
 	
@@ -192,7 +1646,7 @@ func (d *Device) setDataDevice(name string) (err error) {
 	
 	
 	
-	ival := C.gocrypt_crypt_set_data_device(
+	ival := C.gocrypt_crypt_suspend(
 		&arglist,
 		d.cd,
 		
@@ -205,10 +1659,15 @@ func (d *Device) setDataDevice(name string) (err error) {
 	return
 }
 
-func (d *Device) keyslotAddByPassphrase(keyslot int, passphrase []byte, new_passphrase []byte) (out int, err error) {
+func (d *Device) resumeByPassphrase(name string, keyslot int, passphrase []byte) (out int, err error) {
 	arglist := (*C.struct_gocrypt_logstack)(nil)
 	
 	
+	_name := C.CString(name)
+	defer C.free(unsafe.Pointer(_name))
+	
+	
+	
 	
 	// not a pointer
 	
@@ -219,10 +1678,14 @@ func (d *Device) keyslotAddByPassphrase(keyslot int, passphrase []byte, new_pass
 	_passphrase := unsafe.Pointer(nil)
 	if passphrase != nil {
 		_passphrase = C.CBytes(passphrase)
-		defer C.free(_passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_passphrase), len(passphrase)))
+			C.free(_passphrase)
+		}()
 	} else {
 		
-		// this value can be nil
+		panic("nil unexpected")
 		
 	}
 	
@@ -235,38 +1698,73 @@ func (d *Device) keyslotAddByPassphrase(keyslot int, passphrase []byte, new_pass
 	
 	
 	
-	_new_passphrase := unsafe.Pointer(nil)
-	if new_passphrase != nil {
-		_new_passphrase = C.CBytes(new_passphrase)
-		defer C.free(_new_passphrase)
-	} else {
+	ival := C.gocrypt_crypt_resume_by_passphrase(
+		&arglist,
+		d.cd,
 		
-		panic("nil unexpected")
+		_name,
 		
-	}
+		_keyslot,
+		
+		_passphrase,
+		
+		_passphrase_size,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	out = (int)(ival)
+	return
+}
+
+func (d *Device) resumeByKeyfileDeviceOffset(name string, keyslot int, keyfile string, keyfile_size uint64, keyfile_offset uint64) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	_name := C.CString(name)
+	defer C.free(unsafe.Pointer(_name))
 	
 	
 	
 	
 	// not a pointer
 	
-	_new_passphrase_size := (C.size_t)(len(new_passphrase))
+	_keyslot := (C.int)(keyslot)
 	
 	
 	
-	ival := C.gocrypt_crypt_keyslot_add_by_passphrase(
+	_keyfile := C.CString(keyfile)
+	defer C.free(unsafe.Pointer(_keyfile))
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyfile_size := (C.size_t)(keyfile_size)
+	
+	
+	
+	
+	// not a pointer
+	
+	_keyfile_offset := (C.uint64_t)(keyfile_offset)
+	
+	
+	
+	ival := C.gocrypt_crypt_resume_by_keyfile_device_offset(
 		&arglist,
 		d.cd,
 		
-		_keyslot,
+		_name,
 		
-		_passphrase,
+		_keyslot,
 		
-		_passphrase_size,
+		_keyfile,
 		
-		_new_passphrase,
+		_keyfile_size,
 		
-		_new_passphrase_size,
+		_keyfile_offset,
 		
 	)
 	
@@ -275,22 +1773,47 @@ func (d *Device) keyslotAddByPassphrase(keyslot int, passphrase []byte, new_pass
 	return
 }
 
-func (d *Device) keyslotDestroy(keyslot int) (err error) {
+func (d *Device) resumeByVolumeKey(name string, volume_key []byte) (err error) {
 	arglist := (*C.struct_gocrypt_logstack)(nil)
 	
 	
+	_name := C.CString(name)
+	defer C.free(unsafe.Pointer(_name))
+	
+	
+	
+	_volume_key := unsafe.Pointer(nil)
+	if volume_key != nil {
+		_volume_key = C.CBytes(volume_key)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_volume_key), len(volume_key)))
+			C.free(_volume_key)
+		}()
+	} else {
+		
+		panic("nil unexpected")
+		
+	}
+	
+	
+	
 	
 	// not a pointer
 	
-	_keyslot := (C.int)(keyslot)
+	_volume_key_size := (C.size_t)(len(volume_key))
 	
 	
 	
-	ival := C.gocrypt_crypt_keyslot_destroy(
+	ival := C.gocrypt_crypt_resume_by_volume_key(
 		&arglist,
 		d.cd,
 		
-		_keyslot,
+		_name,
+		
+		_volume_key,
+		
+		_volume_key_size,
 		
 	)
 	
@@ -299,29 +1822,57 @@ func (d *Device) keyslotDestroy(keyslot int) (err error) {
 	return
 }
 
-func (d *Device) activateByPassphrase(name *string, keyslot int, passphrase []byte, flags uint32) (out int, err error) {
+func (d *Device) resize(name string, new_size uint64) (err error) {
 	arglist := (*C.struct_gocrypt_logstack)(nil)
 	
 	
-	var _name *C.char
-	if name != nil {
-		_name = C.CString(*name)
-		defer C.free(unsafe.Pointer(_name))
-	}
+	_name := C.CString(name)
+	defer C.free(unsafe.Pointer(_name))
 	
 	
 	
 	
 	// not a pointer
 	
-	_keyslot := (C.int)(keyslot)
+	_new_size := (C.uint64_t)(new_size)
+	
+	
+	
+	ival := C.gocrypt_crypt_resize(
+		&arglist,
+		d.cd,
+		
+		_name,
+		
+		_new_size,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) reencryptInitByPassphrase(name *string, passphrase []byte, keyslot_old int, keyslot_new int, cipher *string, cipher_mode *string, params unsafe.Pointer) (out int, err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	var _name *C.char
+	if name != nil {
+		_name = C.CString(*name)
+		defer C.free(unsafe.Pointer(_name))
+	}
 	
 	
 	
 	_passphrase := unsafe.Pointer(nil)
 	if passphrase != nil {
 		_passphrase = C.CBytes(passphrase)
-		defer C.free(_passphrase)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_passphrase), len(passphrase)))
+			C.free(_passphrase)
+		}()
 	} else {
 		
 		panic("nil unexpected")
@@ -340,80 +1891,83 @@ func (d *Device) activateByPassphrase(name *string, keyslot int, passphrase []by
 	
 	// not a pointer
 	
-	_flags := (C.uint32_t)(flags)
+	_keyslot_old := (C.int)(keyslot_old)
 	
 	
 	
-	ival := C.gocrypt_crypt_activate_by_passphrase(
-		&arglist,
-		d.cd,
-		
-		_name,
-		
-		_keyslot,
-		
-		_passphrase,
-		
-		_passphrase_size,
-		
-		_flags,
-		
-	)
 	
-	err = newError(int(ival), logMessages(arglist))
-	out = (int)(ival)
-	return
-}
-
-func (d *Device) getActiveDevice(name string, cad *C.struct_crypt_active_device) (err error) {
-	arglist := (*C.struct_gocrypt_logstack)(nil)
+	// not a pointer
 	
+	_keyslot_new := (C.int)(keyslot_new)
 	
-	_name := C.CString(name)
-	defer C.free(unsafe.Pointer(_name))
 	
 	
+	var _cipher *C.char
+	if cipher != nil {
+		_cipher = C.CString(*cipher)
+		defer C.free(unsafe.Pointer(_cipher))
+	}
 	
 	
 	
-	if cad == nil {
-		panic("nil unexpected")
+	var _cipher_mode *C.char
+	if cipher_mode != nil {
+		_cipher_mode = C.CString(*cipher_mode)
+		defer C.free(unsafe.Pointer(_cipher_mode))
 	}
 	
 	
-	_cad := (*C.struct_crypt_active_device)(cad)
 	
 	
+	// not a pointer
+	
+	_params := (unsafe.Pointer)(params)
+	
 	
-	ival := C.gocrypt_crypt_get_active_device(
+	
+	ival := C.gocrypt_crypt_reencrypt_init_by_passphrase(
 		&arglist,
 		d.cd,
 		
 		_name,
 		
-		_cad,
+		_passphrase,
+		
+		_passphrase_size,
+		
+		_keyslot_old,
+		
+		_keyslot_new,
+		
+		_cipher,
+		
+		_cipher_mode,
+		
+		_params,
 		
 	)
 	
 	err = newError(int(ival), logMessages(arglist))
-	
+	out = (int)(ival)
 	return
 }
 
-func (d *Device) deactivate(name string) (err error) {
+func (d *Device) reencryptRun(usrptr unsafe.Pointer) (err error) {
 	arglist := (*C.struct_gocrypt_logstack)(nil)
 	
 	
-	_name := C.CString(name)
-	defer C.free(unsafe.Pointer(_name))
 	
+	// not a pointer
 	
+	_usrptr := (unsafe.Pointer)(usrptr)
 	
-	ival := C.gocrypt_crypt_deactivate(
+	
+	
+	ival := C.gocrypt_crypt_reencrypt_run(
 		&arglist,
 		d.cd,
 		
-		_name,
+		_usrptr,
 		
 	)
 	
@@ -504,24 +2058,29 @@ func (d *Device) benchmark(cipher string, cipher_mode string, volume_key_size ui
 	return
 }
 
-func (d *Device) benchmarkKdf(kdf string, hash string, password []byte, salt []byte, iterations_sec *C.uint64_t) (err error) {
+func (d *Device) benchmarkPbkdf(pbkdf *C.struct_crypt_pbkdf_type, password []byte, salt []byte, volume_key_size uint64) (err error) {
 	arglist := (*C.struct_gocrypt_logstack)(nil)
 	
 	
-	_kdf := C.CString(kdf)
-	defer C.free(unsafe.Pointer(_kdf))
 	
 	
+	if pbkdf == nil {
+		panic("nil unexpected")
+	}
+	
 	
-	_hash := C.CString(hash)
-	defer C.free(unsafe.Pointer(_hash))
+	_pbkdf := (*C.struct_crypt_pbkdf_type)(pbkdf)
 	
 	
 	
 	_password := unsafe.Pointer(nil)
 	if password != nil {
 		_password = C.CBytes(password)
-		defer C.free(_password)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_password), len(password)))
+			C.free(_password)
+		}()
 	} else {
 		
 		panic("nil unexpected")
@@ -540,7 +2099,11 @@ func (d *Device) benchmarkKdf(kdf string, hash string, password []byte, salt []b
 	_salt := unsafe.Pointer(nil)
 	if salt != nil {
 		_salt = C.CBytes(salt)
-		defer C.free(_salt)
+		defer func() {
+			// the copy may hold a passphrase or key
+			Wipe(unsafe.Slice((*byte)(_salt), len(salt)))
+			C.free(_salt)
+		}()
 	} else {
 		
 		panic("nil unexpected")
@@ -557,23 +2120,17 @@ func (d *Device) benchmarkKdf(kdf string, hash string, password []byte, salt []b
 	
 	
 	
+	// not a pointer
 	
-	if iterations_sec == nil {
-		panic("nil unexpected")
-	}
-	
-	
-	_iterations_sec := (*C.uint64_t)(iterations_sec)
+	_volume_key_size := (C.size_t)(volume_key_size)
 	
 	
 	
-	ival := C.gocrypt_crypt_benchmark_kdf(
+	ival := C.gocrypt_crypt_benchmark_pbkdf(
 		&arglist,
 		d.cd,
 		
-		_kdf,
-		
-		_hash,
+		_pbkdf,
 		
 		_password,
 		
@@ -583,7 +2140,74 @@ func (d *Device) benchmarkKdf(kdf string, hash string, password []byte, salt []b
 		
 		_salt_size,
 		
-		_iterations_sec,
+		_volume_key_size,
+		
+	)
+	
+	err = newError(int(ival), logMessages(arglist))
+	
+	return
+}
+
+func (d *Device) wipe(dev_path string, pattern int, offset uint64, length uint64, wipe_block_size uint64, flags uint32) (err error) {
+	arglist := (*C.struct_gocrypt_logstack)(nil)
+	
+	
+	_dev_path := C.CString(dev_path)
+	defer C.free(unsafe.Pointer(_dev_path))
+	
+	
+	
+	
+	// not a pointer
+	
+	_pattern := (C.int)(pattern)
+	
+	
+	
+	
+	// not a pointer
+	
+	_offset := (C.uint64_t)(offset)
+	
+	
+	
+	
+	// not a pointer
+	
+	_length := (C.uint64_t)(length)
+	
+	
+	
+	
+	// not a pointer
+	
+	_wipe_block_size := (C.size_t)(wipe_block_size)
+	
+	
+	
+	
+	// not a pointer
+	
+	_flags := (C.uint32_t)(flags)
+	
+	
+	
+	ival := C.gocrypt_crypt_wipe(
+		&arglist,
+		d.cd,
+		
+		_dev_path,
+		
+		_pattern,
+		
+		_offset,
+		
+		_length,
+		
+		_wipe_block_size,
+		
+		_flags,
 		
 	)
 	
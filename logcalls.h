@@ -13,25 +13,87 @@ int gocrypt_crypt_format(struct gocrypt_logstack **, struct crypt_device *, cons
 
 int gocrypt_crypt_load(struct gocrypt_logstack **, struct crypt_device *, const char *, void *);
 
+int gocrypt_crypt_header_backup(struct gocrypt_logstack **, struct crypt_device *, const char *, const char *);
+
+int gocrypt_crypt_header_restore(struct gocrypt_logstack **, struct crypt_device *, const char *, const char *);
+
 int gocrypt_crypt_get_rng_type(struct gocrypt_logstack **, struct crypt_device *);
 
+int gocrypt_crypt_dump(struct gocrypt_logstack **, struct crypt_device *);
+
+int gocrypt_crypt_dump_json(struct gocrypt_logstack **, struct crypt_device *, const char **, uint32_t);
+
+int gocrypt_crypt_set_pbkdf_type(struct gocrypt_logstack **, struct crypt_device *, struct crypt_pbkdf_type *);
+
 int gocrypt_crypt_set_uuid(struct gocrypt_logstack **, struct crypt_device *, const char *);
 
 int gocrypt_crypt_set_data_device(struct gocrypt_logstack **, struct crypt_device *, const char *);
 
+int gocrypt_crypt_set_data_offset(struct gocrypt_logstack **, struct crypt_device *, uint64_t);
+
+int gocrypt_crypt_volume_key_keyring(struct gocrypt_logstack **, struct crypt_device *, int);
+
+int gocrypt_crypt_volume_key_get(struct gocrypt_logstack **, struct crypt_device *, int, char *, size_t *, void *, size_t);
+
 int gocrypt_crypt_keyslot_add_by_passphrase(struct gocrypt_logstack **, struct crypt_device *, int, void *, size_t, void *, size_t);
 
+int gocrypt_crypt_keyslot_add_by_volume_key(struct gocrypt_logstack **, struct crypt_device *, int, void *, size_t, void *, size_t);
+
+int gocrypt_crypt_keyslot_add_by_key(struct gocrypt_logstack **, struct crypt_device *, int, void *, size_t, void *, size_t, uint32_t);
+
+int gocrypt_crypt_keyslot_add_by_keyfile_device_offset(struct gocrypt_logstack **, struct crypt_device *, int, const char *, size_t, uint64_t, const char *, size_t, uint64_t);
+
+int gocrypt_crypt_keyslot_change_by_passphrase(struct gocrypt_logstack **, struct crypt_device *, int, int, void *, size_t, void *, size_t);
+
+int gocrypt_crypt_keyfile_device_read(struct gocrypt_logstack **, struct crypt_device *, const char *, char **, size_t *, uint64_t, size_t, uint32_t);
+
 int gocrypt_crypt_keyslot_destroy(struct gocrypt_logstack **, struct crypt_device *, int);
 
+int gocrypt_crypt_keyslot_get_pbkdf(struct gocrypt_logstack **, struct crypt_device *, int, struct crypt_pbkdf_type *);
+
+int gocrypt_crypt_token_json_get(struct gocrypt_logstack **, struct crypt_device *, int, const char **);
+
+int gocrypt_crypt_token_json_set(struct gocrypt_logstack **, struct crypt_device *, int, const char *);
+
+int gocrypt_crypt_token_assign_keyslot(struct gocrypt_logstack **, struct crypt_device *, int, int);
+
+int gocrypt_crypt_token_unassign_keyslot(struct gocrypt_logstack **, struct crypt_device *, int, int);
+
 int gocrypt_crypt_activate_by_passphrase(struct gocrypt_logstack **, struct crypt_device *, const char *, int, void *, size_t, uint32_t);
 
+int gocrypt_crypt_activate_by_keyfile_device_offset(struct gocrypt_logstack **, struct crypt_device *, const char *, int, const char *, size_t, uint64_t, uint32_t);
+
+int gocrypt_crypt_activate_by_volume_key(struct gocrypt_logstack **, struct crypt_device *, const char *, void *, size_t, uint32_t);
+
+int gocrypt_crypt_activate_by_signed_key(struct gocrypt_logstack **, struct crypt_device *, const char *, void *, size_t, void *, size_t, uint32_t);
+
+int gocrypt_crypt_activate_by_token_pin(struct gocrypt_logstack **, struct crypt_device *, const char *, const char *, int, void *, size_t, void *, uint32_t);
+
+int gocrypt_crypt_activate_by_keyring(struct gocrypt_logstack **, struct crypt_device *, const char *, const char *, int, uint32_t);
+
 int gocrypt_crypt_get_active_device(struct gocrypt_logstack **, struct crypt_device *, const char *, struct crypt_active_device *);
 
 int gocrypt_crypt_deactivate(struct gocrypt_logstack **, struct crypt_device *, const char *);
 
+int gocrypt_crypt_suspend(struct gocrypt_logstack **, struct crypt_device *, const char *);
+
+int gocrypt_crypt_resume_by_passphrase(struct gocrypt_logstack **, struct crypt_device *, const char *, int, void *, size_t);
+
+int gocrypt_crypt_resume_by_keyfile_device_offset(struct gocrypt_logstack **, struct crypt_device *, const char *, int, const char *, size_t, uint64_t);
+
+int gocrypt_crypt_resume_by_volume_key(struct gocrypt_logstack **, struct crypt_device *, const char *, void *, size_t);
+
+int gocrypt_crypt_resize(struct gocrypt_logstack **, struct crypt_device *, const char *, uint64_t);
+
+int gocrypt_crypt_reencrypt_init_by_passphrase(struct gocrypt_logstack **, struct crypt_device *, const char *, void *, size_t, int, int, const char *, const char *, void *);
+
+int gocrypt_crypt_reencrypt_run(struct gocrypt_logstack **, struct crypt_device *, void *);
+
 int gocrypt_crypt_benchmark(struct gocrypt_logstack **, struct crypt_device *, const char *, const char *, size_t, size_t, size_t, double *, double *);
 
-int gocrypt_crypt_benchmark_kdf(struct gocrypt_logstack **, struct crypt_device *, const char *, const char *, void *, size_t, void *, size_t, uint64_t *);
+int gocrypt_crypt_benchmark_pbkdf(struct gocrypt_logstack **, struct crypt_device *, struct crypt_pbkdf_type *, void *, size_t, void *, size_t, size_t);
+
+int gocrypt_crypt_wipe(struct gocrypt_logstack **, struct crypt_device *, const char *, int, uint64_t, uint64_t, size_t, uint32_t);
 
 
 #endif /* LOGCALLS_H */
@@ -0,0 +1,57 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"unsafe"
+)
+
+// LoopaesParams is the set of parameters used for opening loop-AES
+// devices in single-key, multi-key v2 or multi-key v3 mode. loop-AES
+// devices have no header, "formatting" one with Format only
+// configures the Device, nothing is written to the block device.
+// Only the Cipher and VolumeKeySize of Params are used, the
+// VolumeKeySize defaults to 16 bytes (AES-128) like loop-AES does.
+type LoopaesParams struct {
+	Params
+	Hash   string // keyfile hash or "" to pick one from the key size
+	Offset uint64 // offset (in sectors)
+	Skip   uint64 // IV offset / initialization sector
+}
+
+func (p LoopaesParams) CMode() (t string, pp Params, out unsafe.Pointer, free func()) {
+	if p.VolumeKeySize == 0 {
+		p.VolumeKeySize = 128 / 8
+	}
+	p.def()
+
+	t = C.CRYPT_LOOPAES
+	pp = p.Params
+	s := C.struct_crypt_params_loopaes{
+		hash:   nil,
+		offset: C.uint64_t(p.Offset),
+		skip:   C.uint64_t(p.Skip),
+	}
+	if p.Hash != "" {
+		s.hash = C.CString(p.Hash)
+	}
+	out = C.malloc(C.sizeof_struct_crypt_params_loopaes)
+	*(*C.struct_crypt_params_loopaes)(out) = s
+	free = func() {
+		C.free(out)
+		C.free(unsafe.Pointer(s.hash))
+	}
+	return
+}
+
+// ActivateLoopaes sets up the loop-AES device configured with
+// LoopaesParams as name under the directory specified by Dir(),
+// reading the keys from keyfile. The keyfile holds one key per line,
+// 1 key in single-key mode, 64 in multi-key v2 and 65 in multi-key
+// v3 mode. If name is nil the keyfile is only checked and nothing is
+// activated. Use ActivateByKeyfile for activation flags.
+func (d *Device) ActivateLoopaes(name *string, keyfile string) error {
+	return d.ActivateByKeyfile(name, keyfile, 0, 0, 0)
+}
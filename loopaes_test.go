@@ -0,0 +1,95 @@
+package cryptsetup
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// makeLoopaesKeyfile writes a loop-AES keyfile with the given number
+// of random keys, one per line.
+func makeLoopaesKeyfile(t *testing.T, keys int) string {
+	lines := make([]string, keys)
+	for i := range lines {
+		b := make([]byte, 33)
+		_, err := rand.Read(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines[i] = base64.StdEncoding.EncodeToString(b)
+	}
+	return makeKeyfile(t, strings.Join(lines, "\n")+"\n")
+}
+
+func TestDevice_Loopaes(t *testing.T) {
+	t.Parallel()
+
+	// single-key, multi-key v2 and multi-key v3
+	for _, keys := range []int{1, 64, 65} {
+		keys := keys
+		t.Run("keys="+strconv.Itoa(keys), func(t *testing.T) {
+			t.Parallel()
+
+			d, f, err := makeDevice()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer freeme(d, f)
+
+			keyfile := makeLoopaesKeyfile(t, keys)
+			defer os.Remove(keyfile)
+
+			err = d.Format(nil, LoopaesParams{Hash: "sha256"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// without a name the keyfile is only checked
+			err = d.ActivateLoopaes(nil, keyfile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if os.Geteuid() == 0 {
+				name := "example_loopaes_device" + strconv.Itoa(keys)
+				err = d.ActivateLoopaes(&name, keyfile)
+				if err != nil {
+					t.Fatal(err)
+				}
+				s, err := d.Status(name)
+				d.Deactivate(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if s.Status != StatusActive {
+					t.Fatalf("unexpected status %+v", s)
+				}
+			}
+		})
+	}
+}
+
+func TestDevice_Loopaes_error(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	// no loop-AES mode uses two keys
+	keyfile := makeLoopaesKeyfile(t, 2)
+	defer os.Remove(keyfile)
+
+	err = d.Format(nil, LoopaesParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.ActivateLoopaes(nil, keyfile)
+	if err == nil {
+		t.Fatal("accepted an invalid keyfile")
+	}
+}
@@ -0,0 +1,53 @@
+// Package luks parses LUKS1 and LUKS2 headers in pure Go, for tools
+// that only need to inspect a header and should not depend on cgo or
+// libcryptsetup. Nothing is ever decrypted or written.
+package luks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrMagic is returned when there is no LUKS header.
+	ErrMagic = errors.New("luks: not a LUKS header")
+
+	// ErrVersion is returned for headers of the wrong version.
+	ErrVersion = errors.New("luks: unsupported header version")
+
+	// ErrChecksum is returned when the checksum of a LUKS2
+	// header does not match its contents.
+	ErrChecksum = errors.New("luks: header checksum mismatch")
+
+	// ErrInvalid is returned for headers with inconsistent
+	// fields or invalid metadata.
+	ErrInvalid = errors.New("luks: invalid header")
+)
+
+var (
+	magic          = []byte("LUKS\xba\xbe")
+	secondaryMagic = []byte("SKUL\xba\xbe")
+)
+
+// Version returns the version of the LUKS header at the start of r.
+func Version(r io.ReaderAt) (int, error) {
+	var b [8]byte
+	_, err := r.ReadAt(b[:], 0)
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(b[:6], magic) {
+		return 0, ErrMagic
+	}
+	return int(binary.BigEndian.Uint16(b[6:])), nil
+}
+
+// cstring returns the NUL terminated string stored in b.
+func cstring(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
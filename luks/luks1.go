@@ -0,0 +1,178 @@
+package luks
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+)
+
+// V1Keyslots is the number of keyslots in a LUKS1 header.
+const V1Keyslots = 8
+
+// V1Size is the size (in bytes) of the LUKS1 binary header, the
+// keyslot areas follow it.
+const V1Size = 592
+
+// LUKS1 offsets are counted in sectors of this size (in bytes)
+const sectorSize = 512
+
+// values of the active field of LUKS1 keyslots
+const (
+	v1KeyEnabled  = 0x00ac71f3
+	v1KeyDisabled = 0x0000dead
+)
+
+// V1Header is a LUKS1 header. Offsets are in 512 byte sectors.
+type V1Header struct {
+	Cipher        string // e.g. "aes"
+	Mode          string // e.g. "xts-plain64"
+	Hash          string // hash used for the digest and keyslots
+	PayloadOffset uint32 // offset of the data
+	KeyBytes      uint32 // volume key size (in bytes)
+
+	// the PBKDF2 digest of the volume key
+	Digest           []byte
+	DigestSalt       []byte
+	DigestIterations uint32
+
+	Uuid     string
+	Keyslots [V1Keyslots]V1Keyslot
+}
+
+// V1Keyslot is a keyslot of a LUKS1 header.
+type V1Keyslot struct {
+	Active            bool
+	Iterations        uint32 // PBKDF2 iterations
+	Salt              []byte
+	KeyMaterialOffset uint32 // offset of the key material
+	Stripes           uint32 // anti-forensic stripes
+}
+
+// v1Disk is the on-disk layout of a LUKS1 header, all integers are
+// big endian.
+type v1Disk struct {
+	Magic              [6]byte
+	Version            uint16
+	CipherName         [32]byte
+	CipherMode         [32]byte
+	HashSpec           [32]byte
+	PayloadOffset      uint32
+	KeyBytes           uint32
+	MkDigest           [20]byte
+	MkDigestSalt       [32]byte
+	MkDigestIterations uint32
+	Uuid               [40]byte
+	Keyslots           [V1Keyslots]struct {
+		Active            uint32
+		Iterations        uint32
+		Salt              [32]byte
+		KeyMaterialOffset uint32
+		Stripes           uint32
+	}
+}
+
+// ReadV1 reads the LUKS1 header at the start of r.
+func ReadV1(r io.ReaderAt) (*V1Header, error) {
+	var d v1Disk
+	err := binary.Read(io.NewSectionReader(r, 0, V1Size), binary.BigEndian, &d)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(d.Magic[:], magic) {
+		return nil, ErrMagic
+	}
+	if d.Version != 1 {
+		return nil, ErrVersion
+	}
+
+	h := &V1Header{
+		Cipher:           cstring(d.CipherName[:]),
+		Mode:             cstring(d.CipherMode[:]),
+		Hash:             cstring(d.HashSpec[:]),
+		PayloadOffset:    d.PayloadOffset,
+		KeyBytes:         d.KeyBytes,
+		Digest:           d.MkDigest[:],
+		DigestSalt:       d.MkDigestSalt[:],
+		DigestIterations: d.MkDigestIterations,
+		Uuid:             cstring(d.Uuid[:]),
+	}
+	for i, k := range d.Keyslots {
+		if k.Active != v1KeyEnabled && k.Active != v1KeyDisabled {
+			return nil, ErrInvalid
+		}
+		h.Keyslots[i] = V1Keyslot{
+			Active:            k.Active == v1KeyEnabled,
+			Iterations:        k.Iterations,
+			Salt:              append([]byte(nil), k.Salt[:]...),
+			KeyMaterialOffset: k.KeyMaterialOffset,
+			Stripes:           k.Stripes,
+		}
+	}
+	return h, nil
+}
+
+// Metadata returns the header in the shape of the LUKS2 metadata, the
+// way it is converted to LUKS2. The volume key has a single segment
+// and digest, each active keyslot becomes a "luks2" keyslot and the
+// Config is left empty as LUKS1 has none.
+func (h *V1Header) Metadata() Metadata {
+	encryption := h.Cipher + "-" + h.Mode
+	m := Metadata{
+		Keyslots: map[int]Keyslot{},
+		Tokens:   map[int]Token{},
+		Segments: map[int]Segment{
+			0: {
+				Type:       "crypt",
+				Offset:     uint64(h.PayloadOffset) * sectorSize,
+				Dynamic:    true,
+				Encryption: encryption,
+				SectorSize: sectorSize,
+			},
+		},
+		Digests: map[int]Digest{
+			0: {
+				Type:       "pbkdf2",
+				Keyslots:   IDs{},
+				Segments:   IDs{0},
+				Hash:       h.Hash,
+				Iterations: int(h.DigestIterations),
+				Salt:       h.DigestSalt,
+				Digest:     h.Digest,
+			},
+		},
+	}
+	for i, k := range h.Keyslots {
+		if !k.Active {
+			continue
+		}
+		// the key material is split into stripes and padded to
+		// whole sectors
+		sectors := (uint64(h.KeyBytes)*uint64(k.Stripes) + sectorSize - 1) / sectorSize
+		m.Keyslots[i] = Keyslot{
+			Type:    "luks2",
+			KeySize: int(h.KeyBytes),
+			Af: Af{
+				Type:    "luks1",
+				Stripes: int(k.Stripes),
+				Hash:    h.Hash,
+			},
+			Area: KeyslotArea{
+				Type:       "raw",
+				Offset:     uint64(k.KeyMaterialOffset) * sectorSize,
+				Size:       sectors * sectorSize,
+				Encryption: encryption,
+				KeySize:    int(h.KeyBytes),
+			},
+			Kdf: Kdf{
+				Type:       "pbkdf2",
+				Salt:       k.Salt,
+				Hash:       h.Hash,
+				Iterations: int(k.Iterations),
+			},
+		}
+		d := m.Digests[0]
+		d.Keyslots = append(d.Keyslots, i)
+		m.Digests[0] = d
+	}
+	return m
+}
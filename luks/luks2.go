@@ -0,0 +1,179 @@
+package luks
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"hash"
+	"io"
+)
+
+// V2BinarySize is the size (in bytes) of the binary part of a LUKS2
+// header, the JSON metadata follows it.
+const V2BinarySize = 4096
+
+// v2Offsets are the offsets (in bytes) at which the secondary LUKS2
+// header can be found, one for every valid header size.
+var v2Offsets = []int64{
+	0x4000, 0x8000, 0x10000, 0x20000, 0x40000,
+	0x80000, 0x100000, 0x200000, 0x400000,
+}
+
+// V2 holds both copies of a LUKS2 header. A copy that could not be
+// read is nil and the reason is in the matching error.
+type V2 struct {
+	Primary      *V2Header
+	PrimaryErr   error
+	Secondary    *V2Header
+	SecondaryErr error
+}
+
+// Header returns the copy of the header that libcryptsetup uses, the
+// valid one with the highest sequence id.
+func (v *V2) Header() *V2Header {
+	if v.Secondary != nil && (v.Primary == nil || v.Secondary.Seqid > v.Primary.Seqid) {
+		return v.Secondary
+	}
+	return v.Primary
+}
+
+// V2Header is one copy of a LUKS2 header.
+type V2Header struct {
+	Offset            int64  // offset (in bytes) of this copy
+	Size              uint64 // size (in bytes) including the JSON area
+	Seqid             uint64 // incremented on every update
+	Label             string
+	ChecksumAlgorithm string // e.g. "sha256"
+	Salt              []byte
+	Uuid              string
+	Subsystem         string
+	Checksum          []byte
+
+	// JSON is the raw metadata that Metadata was parsed from.
+	JSON     []byte
+	Metadata Metadata
+}
+
+// v2Disk is the on-disk layout of the binary LUKS2 header, all
+// integers are big endian.
+type v2Disk struct {
+	Magic       [6]byte
+	Version     uint16
+	HdrSize     uint64
+	Seqid       uint64
+	Label       [48]byte
+	ChecksumAlg [32]byte
+	Salt        [64]byte
+	Uuid        [40]byte
+	Subsystem   [48]byte
+	HdrOffset   uint64
+	_           [184]byte
+	Csum        [64]byte
+}
+
+// ReadV2 reads both copies of the LUKS2 header in r. It only fails
+// if neither copy is valid, returning the error of the primary one.
+func ReadV2(r io.ReaderAt) (*V2, error) {
+	v := &V2{}
+	v.Primary, v.PrimaryErr = readV2Header(r, 0, magic)
+	if v.Primary != nil {
+		v.Secondary, v.SecondaryErr = readV2Header(r, int64(v.Primary.Size), secondaryMagic)
+	} else {
+		// the size of the primary header is not known, look
+		// for the secondary one everywhere it can be
+		v.SecondaryErr = ErrMagic
+		for _, off := range v2Offsets {
+			v.Secondary, v.SecondaryErr = readV2Header(r, off, secondaryMagic)
+			if v.Secondary != nil {
+				break
+			}
+		}
+	}
+	if v.Primary == nil && v.Secondary == nil {
+		return nil, v.PrimaryErr
+	}
+	return v, nil
+}
+
+// readV2Header reads the copy of the LUKS2 header at offset off,
+// which must start with m.
+func readV2Header(r io.ReaderAt, off int64, m []byte) (*V2Header, error) {
+	bin := make([]byte, V2BinarySize)
+	_, err := r.ReadAt(bin, off)
+	if err != nil {
+		return nil, err
+	}
+	var d v2Disk
+	err = binary.Read(bytes.NewReader(bin), binary.BigEndian, &d)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(d.Magic[:], m) {
+		return nil, ErrMagic
+	}
+	if d.Version != 2 {
+		return nil, ErrVersion
+	}
+	if d.HdrOffset != uint64(off) || !validV2Size(d.HdrSize) {
+		return nil, ErrInvalid
+	}
+
+	area := make([]byte, d.HdrSize-V2BinarySize)
+	_, err = r.ReadAt(area, off+V2BinarySize)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &V2Header{
+		Offset:            off,
+		Size:              d.HdrSize,
+		Seqid:             d.Seqid,
+		Label:             cstring(d.Label[:]),
+		ChecksumAlgorithm: cstring(d.ChecksumAlg[:]),
+		Salt:              d.Salt[:],
+		Uuid:              cstring(d.Uuid[:]),
+		Subsystem:         cstring(d.Subsystem[:]),
+	}
+
+	// the checksum covers the whole header with the checksum
+	// field zeroed
+	var hh hash.Hash
+	switch h.ChecksumAlgorithm {
+	case "sha1":
+		hh = sha1.New()
+	case "sha256":
+		hh = sha256.New()
+	case "sha512":
+		hh = sha512.New()
+	default:
+		return nil, ErrInvalid
+	}
+	h.Checksum = d.Csum[:hh.Size()]
+	csum := binary.Size(d) - len(d.Csum)
+	copy(bin[csum:], make([]byte, len(d.Csum)))
+	hh.Write(bin)
+	hh.Write(area)
+	if !bytes.Equal(hh.Sum(nil), h.Checksum) {
+		return nil, ErrChecksum
+	}
+
+	h.JSON = []byte(cstring(area))
+	err = json.Unmarshal(h.JSON, &h.Metadata)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	return h, nil
+}
+
+// validV2Size reports whether size is one of the LUKS2 header sizes.
+func validV2Size(size uint64) bool {
+	for _, off := range v2Offsets {
+		if size == uint64(off) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package luks_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	cryptsetup "github.com/kcolford/go-cryptsetup"
+	"github.com/kcolford/go-cryptsetup/luks"
+)
+
+var fastPbkdf = cryptsetup.PbkdfParams{
+	Type:       cryptsetup.KdfPbkdf2,
+	Iterations: 1000,
+	Flags:      cryptsetup.PbkdfNoBenchmark,
+}
+
+// makeImage formats a new image of the given size with p and returns
+// the start of it, which holds the headers.
+func makeImage(t testing.TB, size, headers int64, p cryptsetup.CryptParameter, setup func(d *cryptsetup.Device)) []byte {
+	f, err := ioutil.TempFile("", "go-cryptsetup_luks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+	err = f.Truncate(size)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := cryptsetup.NewDevice(f.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	err = d.SetPbkdf(fastPbkdf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Format([]byte("my password"), p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if setup != nil {
+		setup(d)
+	}
+
+	b := make([]byte, headers)
+	_, err = f.ReadAt(b, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func makeLuks1(t testing.TB) []byte {
+	return makeImage(t, 2<<20, luks.V2BinarySize, cryptsetup.LuksParams{}, nil)
+}
+
+func makeLuks2(t testing.TB) []byte {
+	return makeImage(t, 32<<20, 2*0x4000, cryptsetup.Luks2Params{Label: "label"}, func(d *cryptsetup.Device) {
+		_, err := d.SetTokenJSON(cryptsetup.AnyToken, `{"type":"luks2-keyring","keyslots":["0"],"key_description":"example"}`)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestReadV1(t *testing.T) {
+	t.Parallel()
+
+	b := makeLuks1(t)
+	v, err := luks.Version(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 1 {
+		t.Fatalf("got version %d, want 1", v)
+	}
+	h, err := luks.ReadV1(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Cipher != cryptsetup.DefaultCipher || h.Mode != cryptsetup.DefaultMode || h.KeyBytes != 32 || h.Uuid == "" {
+		t.Fatalf("unexpected header %+v", h)
+	}
+	for i, k := range h.Keyslots {
+		if k.Active != (i == 0) {
+			t.Errorf("keyslot %d is active: %v", i, k.Active)
+		}
+	}
+
+	_, err = luks.ReadV2(bytes.NewReader(b))
+	if err != luks.ErrVersion {
+		t.Fatalf("got %v, want %v", err, luks.ErrVersion)
+	}
+}
+
+func TestReadV2(t *testing.T) {
+	t.Parallel()
+
+	b := makeLuks2(t)
+	v, err := luks.Version(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 2 {
+		t.Fatalf("got version %d, want 2", v)
+	}
+	h, err := luks.ReadV2(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.PrimaryErr != nil || h.SecondaryErr != nil {
+		t.Fatal(h.PrimaryErr, h.SecondaryErr)
+	}
+	if h.Primary.Seqid != h.Secondary.Seqid || h.Primary.Uuid != h.Secondary.Uuid {
+		t.Fatalf("header copies differ: %+v and %+v", h.Primary, h.Secondary)
+	}
+
+	hdr := h.Header()
+	if hdr.Label != "label" || hdr.Offset != 0 || hdr.Size != 0x4000 {
+		t.Fatalf("unexpected header %+v", hdr)
+	}
+	m := hdr.Metadata
+	k, ok := m.Keyslots[0]
+	if !ok || len(m.Keyslots) != 1 || k.Type != "luks2" || k.Kdf.Type != "pbkdf2" || k.Kdf.Iterations != 1000 {
+		t.Fatalf("unexpected keyslots %+v", m.Keyslots)
+	}
+	s, ok := m.Segments[0]
+	if !ok || s.Type != "crypt" || !s.Dynamic || s.Encryption != "aes-xts-plain64" {
+		t.Fatalf("unexpected segments %+v", m.Segments)
+	}
+	dg, ok := m.Digests[0]
+	if !ok || len(dg.Keyslots) != 1 || dg.Keyslots[0] != 0 || len(dg.Segments) != 1 {
+		t.Fatalf("unexpected digests %+v", m.Digests)
+	}
+	tk, ok := m.Tokens[0]
+	if !ok || tk.Type != "luks2-keyring" || tk.KeyDescription != "example" || len(tk.Raw) == 0 {
+		t.Fatalf("unexpected tokens %+v", m.Tokens)
+	}
+	if m.Config.JSONSize != 0x4000-luks.V2BinarySize || m.Config.KeyslotsSize == 0 {
+		t.Fatalf("unexpected config %+v", m.Config)
+	}
+
+	_, err = luks.ReadV1(bytes.NewReader(b))
+	if err != luks.ErrVersion {
+		t.Fatalf("got %v, want %v", err, luks.ErrVersion)
+	}
+}
+
+func TestReadV2_corrupt(t *testing.T) {
+	t.Parallel()
+
+	b := makeLuks2(t)
+
+	// damage the metadata of the primary header
+	b[luks.V2BinarySize+1] ^= 0xff
+	h, err := luks.ReadV2(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Primary != nil || h.PrimaryErr != luks.ErrChecksum {
+		t.Fatalf("got %v, want %v", h.PrimaryErr, luks.ErrChecksum)
+	}
+	if h.Header() != h.Secondary || h.Secondary.Offset != 0x4000 {
+		t.Fatalf("unexpected secondary header %+v", h.Secondary)
+	}
+
+	// and then of the secondary one as well
+	b[0x4000+luks.V2BinarySize+1] ^= 0xff
+	_, err = luks.ReadV2(bytes.NewReader(b))
+	if err != luks.ErrChecksum {
+		t.Fatalf("got %v, want %v", err, luks.ErrChecksum)
+	}
+}
+
+func TestVersion_error(t *testing.T) {
+	t.Parallel()
+
+	_, err := luks.Version(bytes.NewReader(make([]byte, luks.V2BinarySize)))
+	if !errors.Is(err, luks.ErrMagic) {
+		t.Fatalf("got %v, want %v", err, luks.ErrMagic)
+	}
+}
+
+func FuzzReadV1(f *testing.F) {
+	f.Add(makeLuks1(f))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		h, err := luks.ReadV1(bytes.NewReader(b))
+		if err == nil && h == nil {
+			t.Fatal("no header and no error")
+		}
+	})
+}
+
+func FuzzReadV2(f *testing.F) {
+	f.Add(makeLuks2(f))
+	f.Fuzz(func(t *testing.T, b []byte) {
+		v, err := luks.ReadV2(bytes.NewReader(b))
+		if err != nil {
+			return
+		}
+		h := v.Header()
+		if h == nil {
+			t.Fatal("no header and no error")
+		}
+		if uint64(len(h.JSON)) > h.Size-luks.V2BinarySize {
+			t.Fatalf("metadata of %d bytes in a %d byte header", len(h.JSON), h.Size)
+		}
+	})
+}
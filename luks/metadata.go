@@ -0,0 +1,195 @@
+package luks
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// Metadata is the JSON metadata of a LUKS2 header. Objects are keyed
+// by their id. Offsets and sizes are in bytes.
+type Metadata struct {
+	Keyslots map[int]Keyslot `json:"keyslots"`
+	Tokens   map[int]Token   `json:"tokens"`
+	Segments map[int]Segment `json:"segments"`
+	Digests  map[int]Digest  `json:"digests"`
+	Config   Config          `json:"config"`
+}
+
+// IDs is a list of object ids, which the metadata stores as strings.
+type IDs []int
+
+func (ids *IDs) UnmarshalJSON(b []byte) error {
+	var s []string
+	err := json.Unmarshal(b, &s)
+	if err != nil {
+		return err
+	}
+	*ids = make(IDs, len(s))
+	for i, id := range s {
+		(*ids)[i], err = strconv.Atoi(id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ids IDs) MarshalJSON() ([]byte, error) {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return json.Marshal(s)
+}
+
+// Keyslot is a LUKS2 keyslot, of type "luks2" for keyslots holding a
+// volume key or "reencrypt" for the state of an online
+// reencryption.
+type Keyslot struct {
+	Type     string `json:"type"`
+	KeySize  int    `json:"key_size"`
+	Priority *int   `json:"priority,omitempty"` // 0 ignore, 1 normal, 2 high
+
+	Af   Af          `json:"af"`
+	Area KeyslotArea `json:"area"`
+	Kdf  Kdf         `json:"kdf"`
+
+	// reencrypt keyslots only
+	Mode      string `json:"mode,omitempty"`      // "reencrypt", "encrypt" or "decrypt"
+	Direction string `json:"direction,omitempty"` // "forward" or "backward"
+}
+
+// Af is the anti-forensic splitter of a keyslot.
+type Af struct {
+	Type    string `json:"type"` // "luks1"
+	Stripes int    `json:"stripes"`
+	Hash    string `json:"hash"`
+}
+
+// KeyslotArea is where a keyslot keeps its key material, or for
+// reencrypt keyslots the resilience mode and its data.
+type KeyslotArea struct {
+	Type       string `json:"type"` // "raw", "none", "checksum", "journal" or "datashift"
+	Offset     uint64 `json:"offset,string"`
+	Size       uint64 `json:"size,string"`
+	Encryption string `json:"encryption,omitempty"`
+	KeySize    int    `json:"key_size,omitempty"`
+
+	Hash       string `json:"hash,omitempty"`              // checksum resilience
+	SectorSize int    `json:"sector_size,omitempty"`       // checksum resilience
+	ShiftSize  uint64 `json:"shift_size,string,omitempty"` // datashift resilience
+}
+
+// Kdf is the key derivation function of a keyslot. Hash and
+// Iterations are used by "pbkdf2", Time, Memory (in KiB) and Cpus by
+// "argon2i" and "argon2id".
+type Kdf struct {
+	Type       string `json:"type"`
+	Salt       []byte `json:"salt"`
+	Hash       string `json:"hash,omitempty"`
+	Iterations int    `json:"iterations,omitempty"`
+	Time       int    `json:"time,omitempty"`
+	Memory     int    `json:"memory,omitempty"`
+	Cpus       int    `json:"cpus,omitempty"`
+}
+
+// Token is a LUKS2 token. Tokens may hold any data, the fields of
+// the built-in "luks2-keyring" type are parsed and the whole object
+// is kept in Raw.
+type Token struct {
+	Type           string `json:"type"`
+	Keyslots       IDs    `json:"keyslots"`
+	KeyDescription string `json:"key_description,omitempty"`
+
+	Raw json.RawMessage `json:"-"`
+}
+
+func (t *Token) UnmarshalJSON(b []byte) error {
+	type token Token
+	err := json.Unmarshal(b, (*token)(t))
+	if err != nil {
+		return err
+	}
+	t.Raw = append(json.RawMessage(nil), b...)
+	return nil
+}
+
+func (t Token) MarshalJSON() ([]byte, error) {
+	if t.Raw != nil {
+		return t.Raw, nil
+	}
+	type token Token
+	return json.Marshal(token(t))
+}
+
+// Segment is a LUKS2 data segment.
+type Segment struct {
+	Type       string `json:"type"` // "crypt" or "linear"
+	Offset     uint64 `json:"offset,string"`
+	Size       uint64 `json:"-"`
+	Dynamic    bool   `json:"-"` // the segment extends to the end of the device
+	IvTweak    uint64 `json:"iv_tweak,string,omitempty"`
+	Encryption string `json:"encryption,omitempty"`
+	SectorSize int    `json:"sector_size,omitempty"`
+
+	Integrity *SegmentIntegrity `json:"integrity,omitempty"`
+	Flags     []string          `json:"flags,omitempty"`
+}
+
+func (s *Segment) UnmarshalJSON(b []byte) error {
+	type segment Segment
+	v := struct {
+		*segment
+		Size string `json:"size"`
+	}{segment: (*segment)(s)}
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return err
+	}
+	s.Dynamic = v.Size == "dynamic"
+	if !s.Dynamic {
+		s.Size, err = strconv.ParseUint(v.Size, 10, 64)
+	}
+	return err
+}
+
+func (s Segment) MarshalJSON() ([]byte, error) {
+	type segment Segment
+	size := "dynamic"
+	if !s.Dynamic {
+		size = strconv.FormatUint(s.Size, 10)
+	}
+	return json.Marshal(struct {
+		segment
+		Size string `json:"size"`
+	}{segment(s), size})
+}
+
+// SegmentIntegrity is the integrity protection of a segment.
+type SegmentIntegrity struct {
+	Type              string `json:"type"` // e.g. "hmac(sha256)"
+	JournalEncryption string `json:"journal_encryption"`
+	JournalIntegrity  string `json:"journal_integrity"`
+}
+
+// Digest verifies the volume key of the segments that it is
+// assigned to, along with the keyslots holding that key.
+type Digest struct {
+	Type       string `json:"type"` // "pbkdf2"
+	Keyslots   IDs    `json:"keyslots"`
+	Segments   IDs    `json:"segments"`
+	Hash       string `json:"hash"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Digest     []byte `json:"digest"`
+}
+
+// Config is the global configuration of a LUKS2 header.
+type Config struct {
+	JSONSize     uint64   `json:"json_size,string"`
+	KeyslotsSize uint64   `json:"keyslots_size,string"`
+	Flags        []string `json:"flags,omitempty"` // persistent activation flags
+	Requirements struct {
+		Mandatory []string `json:"mandatory,omitempty"`
+	} `json:"requirements"`
+}
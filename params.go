@@ -5,6 +5,7 @@ package cryptsetup
 // #include <stdlib.h>
 import "C"
 import (
+	"time"
 	"unsafe"
 )
 
@@ -124,3 +125,155 @@ func (p LuksParams) CMode() (t string, pp Params, out unsafe.Pointer, free func(
 	}
 	return
 }
+
+// Key derivation functions supported by libcryptsetup.
+const (
+	KdfPbkdf2   = C.CRYPT_KDF_PBKDF2
+	KdfArgon2i  = C.CRYPT_KDF_ARGON2I
+	KdfArgon2id = C.CRYPT_KDF_ARGON2ID
+)
+
+// PbkdfFlags modify how the key derivation function is tuned.
+type PbkdfFlags uint32
+
+const (
+	// PbkdfIterTimeSet marks Time as set explicitly by the user.
+	PbkdfIterTimeSet PbkdfFlags = C.CRYPT_PBKDF_ITER_TIME_SET
+
+	// PbkdfNoBenchmark uses Iterations, MaxMemoryKb and
+	// ParallelThreads as given instead of benchmarking them.
+	PbkdfNoBenchmark PbkdfFlags = C.CRYPT_PBKDF_NO_BENCHMARK
+)
+
+// PbkdfParams describes the key derivation function used to turn a
+// passphrase into a keyslot key.
+type PbkdfParams struct {
+	Type            string        // KdfPbkdf2, KdfArgon2i or KdfArgon2id
+	Hash            string        // hash used by pbkdf2
+	Time            time.Duration // time spent deriving the key
+	Iterations      uint32        // iterations (pbkdf2) or time cost (argon2)
+	MaxMemoryKb     uint32        // memory cost (argon2 only)
+	ParallelThreads uint32        // parallel threads (argon2 only)
+	Flags           PbkdfFlags
+}
+
+func newPbkdfParams(s *C.struct_crypt_pbkdf_type) (p PbkdfParams) {
+	if s == nil {
+		return
+	}
+	p.Type = C.GoString(s._type)
+	p.Hash = C.GoString(s.hash)
+	p.Time = time.Duration(s.time_ms) * time.Millisecond
+	p.Iterations = uint32(s.iterations)
+	p.MaxMemoryKb = uint32(s.max_memory_kb)
+	p.ParallelThreads = uint32(s.parallel_threads)
+	p.Flags = PbkdfFlags(s.flags)
+	return
+}
+
+// def fills in the library's default time and costs for p.Type.
+func (p *PbkdfParams) def() {
+	t := C.CString(p.Type)
+	defer C.free(unsafe.Pointer(t))
+	def := newPbkdfParams(C.crypt_get_pbkdf_type_params(t))
+	if p.Hash == "" {
+		p.Hash = DefaultHash
+	}
+	if p.Time == 0 {
+		p.Time = def.Time
+	}
+	if p.Time == 0 {
+		p.Time = 2 * time.Second
+	}
+	if p.Iterations == 0 {
+		p.Iterations = def.Iterations
+	}
+	if p.MaxMemoryKb == 0 {
+		p.MaxMemoryKb = def.MaxMemoryKb
+	}
+	if p.ParallelThreads == 0 {
+		p.ParallelThreads = def.ParallelThreads
+	}
+}
+
+func (p *PbkdfParams) c() (out *C.struct_crypt_pbkdf_type, free func()) {
+	if p == nil {
+		return nil, func() {}
+	}
+	q := *p
+	p = &q
+	p.def()
+
+	s := C.struct_crypt_pbkdf_type{
+		_type:            C.CString(p.Type),
+		hash:             C.CString(p.Hash),
+		time_ms:          C.uint32_t(p.Time / time.Millisecond),
+		iterations:       C.uint32_t(p.Iterations),
+		max_memory_kb:    C.uint32_t(p.MaxMemoryKb),
+		parallel_threads: C.uint32_t(p.ParallelThreads),
+		flags:            C.uint32_t(p.Flags),
+	}
+	out = (*C.struct_crypt_pbkdf_type)(C.malloc(C.sizeof_struct_crypt_pbkdf_type))
+	*out = s
+	free = func() {
+		C.free(unsafe.Pointer(out))
+		C.free(unsafe.Pointer(s._type))
+		C.free(unsafe.Pointer(s.hash))
+	}
+	return
+}
+
+// Luks2Params is the set of parameters used for defining operations
+// on LUKS2 based encrypted devices.
+type Luks2Params struct {
+	Params
+	Pbkdf         *PbkdfParams // keyslot KDF or nil for the default
+	Integrity     string       // integrity algorithm (e.g. "hmac(sha256)") or ""
+	NoWipe        bool         // skip wiping the device when Integrity is set
+	DataAlignment uint64       // data alignment (in sectors)
+	DataDevice    *string      // detached encrypted data device or nil
+	SectorSize    uint32       // encryption sector size (in bytes) or 0
+	Label         string       // header label or ""
+	Subsystem     string       // header subsystem or ""
+}
+
+func (p Luks2Params) CMode() (t string, pp Params, out unsafe.Pointer, free func()) {
+	p.def()
+
+	t = C.CRYPT_LUKS2
+	pp = p.Params
+	pbkdf, freePbkdf := p.Pbkdf.c()
+	s := C.struct_crypt_params_luks2{
+		pbkdf:          pbkdf,
+		integrity:      nil,
+		data_alignment: C.size_t(p.DataAlignment),
+		data_device:    nil,
+		sector_size:    C.uint32_t(p.SectorSize),
+		label:          nil,
+		subsystem:      nil,
+	}
+	if p.Integrity != "" {
+		s.integrity = C.CString(p.Integrity)
+	}
+	if p.DataDevice != nil {
+		s.data_device = C.CString(*p.DataDevice)
+	}
+	if p.Label != "" {
+		s.label = C.CString(p.Label)
+	}
+	if p.Subsystem != "" {
+		s.subsystem = C.CString(p.Subsystem)
+	}
+	out = C.malloc(C.sizeof_struct_crypt_params_luks2)
+	*(*C.struct_crypt_params_luks2)(out) = s
+	free = func() {
+		C.free(out)
+		freePbkdf()
+		// C.free is a no-op on nil pointers
+		C.free(unsafe.Pointer(s.integrity))
+		C.free(unsafe.Pointer(s.data_device))
+		C.free(unsafe.Pointer(s.label))
+		C.free(unsafe.Pointer(s.subsystem))
+	}
+	return
+}
@@ -0,0 +1,8 @@
+/* reencryption functions */
+
+#include "reencrypt.h"
+
+int gocrypt_reencrypt_progress(uint64_t size, uint64_t offset, void *usrptr) {
+  extern int golang_gocrypt_reencrypt_progress(uint64_t, uint64_t, void *);
+  return golang_gocrypt_reencrypt_progress(size, offset, usrptr);
+}
@@ -0,0 +1,358 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+// #include <stdlib.h>
+// #include "reencrypt.h"
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// ReencryptMode selects what a reencryption does to the data.
+type ReencryptMode int
+
+const (
+	ReencryptReencrypt ReencryptMode = C.CRYPT_REENCRYPT_REENCRYPT // change the volume key and cipher
+	ReencryptEncrypt   ReencryptMode = C.CRYPT_REENCRYPT_ENCRYPT   // encrypt plaintext data
+	ReencryptDecrypt   ReencryptMode = C.CRYPT_REENCRYPT_DECRYPT   // decrypt to plaintext data
+)
+
+// ReencryptDirection selects which end of the device is processed
+// first.
+type ReencryptDirection int
+
+const (
+	ReencryptForward  ReencryptDirection = C.CRYPT_REENCRYPT_FORWARD
+	ReencryptBackward ReencryptDirection = C.CRYPT_REENCRYPT_BACKWARD
+)
+
+// Resilience modes protect the data being reencrypted (the hotzone)
+// against a crash.
+const (
+	ResilienceNone      = "none"
+	ResilienceChecksum  = "checksum"  // checksums of the hotzone in the header
+	ResilienceJournal   = "journal"   // a copy of the hotzone in the header
+	ResilienceDatashift = "datashift" // the data is moved by DataShift sectors
+)
+
+// ReencryptFlags modify how a reencryption is set up.
+type ReencryptFlags uint32
+
+const (
+	// ReencryptInitializeOnly only writes the reencryption
+	// metadata, the reencryption is then started with
+	// ReencryptResume.
+	ReencryptInitializeOnly ReencryptFlags = C.CRYPT_REENCRYPT_INITIALIZE_ONLY
+
+	// ReencryptMoveFirstSegment moves the first data segment when
+	// encrypting with a data shift, making room for the header.
+	ReencryptMoveFirstSegment ReencryptFlags = C.CRYPT_REENCRYPT_MOVE_FIRST_SEGMENT
+
+	// ReencryptRecovery repairs a crashed reencryption.
+	ReencryptRecovery ReencryptFlags = C.CRYPT_REENCRYPT_RECOVERY
+)
+
+// ReencryptStatus is the state of a LUKS2 reencryption.
+type ReencryptStatus int
+
+const (
+	ReencryptNone    ReencryptStatus = C.CRYPT_REENCRYPT_NONE  // no reencryption in progress
+	ReencryptClean   ReencryptStatus = C.CRYPT_REENCRYPT_CLEAN // interrupted, can be resumed
+	ReencryptCrash   ReencryptStatus = C.CRYPT_REENCRYPT_CRASH // crashed, needs recovery
+	ReencryptInvalid ReencryptStatus = C.CRYPT_REENCRYPT_INVALID
+)
+
+func (s ReencryptStatus) String() string {
+	switch s {
+	case ReencryptNone:
+		return "none"
+	case ReencryptClean:
+		return "clean"
+	case ReencryptCrash:
+		return "crash"
+	}
+	return "invalid"
+}
+
+// ReencryptParams is the set of parameters used for reencrypting a
+// LUKS2 device. All sizes are in 512 byte sectors.
+type ReencryptParams struct {
+	Mode           ReencryptMode
+	Direction      ReencryptDirection
+	Resilience     string      // one of the Resilience modes, ResilienceChecksum if ""
+	Hash           string      // hash used by ResilienceChecksum
+	DataShift      uint64      // data shift used by ResilienceDatashift
+	MaxHotzoneSize uint64      // maximum size of the hotzone or 0
+	DeviceSize     uint64      // size of the data to reencrypt or 0 for all
+	Luks2          Luks2Params // new cipher and segment parameters, the device's if unset
+	Flags          ReencryptFlags
+
+	// Passphrases unlock the other active keyslots of the device
+	// when reencrypting. Each of them gets a keyslot for the new
+	// volume key, as the old keyslots are removed once the
+	// reencryption finishes.
+	Passphrases [][]byte
+}
+
+func (p ReencryptParams) c() (out *C.struct_crypt_params_reencrypt, pp Params, free func()) {
+	if p.Hash == "" && p.Resilience == ResilienceChecksum {
+		p.Hash = DefaultHash
+	}
+
+	_, pp, luks2, freeLuks2 := p.Luks2.CMode()
+	s := C.struct_crypt_params_reencrypt{
+		mode:             C.crypt_reencrypt_mode_info(p.Mode),
+		direction:        C.crypt_reencrypt_direction_info(p.Direction),
+		resilience:       nil,
+		hash:             nil,
+		data_shift:       C.uint64_t(p.DataShift),
+		max_hotzone_size: C.uint64_t(p.MaxHotzoneSize),
+		device_size:      C.uint64_t(p.DeviceSize),
+		luks2:            (*C.struct_crypt_params_luks2)(luks2),
+		flags:            C.uint32_t(p.Flags),
+	}
+	if p.Resilience != "" {
+		s.resilience = C.CString(p.Resilience)
+	}
+	if p.Hash != "" {
+		s.hash = C.CString(p.Hash)
+	}
+	out = (*C.struct_crypt_params_reencrypt)(C.malloc(C.sizeof_struct_crypt_params_reencrypt))
+	*out = s
+	free = func() {
+		C.free(unsafe.Pointer(out))
+		freeLuks2()
+		C.free(unsafe.Pointer(s.resilience))
+		C.free(unsafe.Pointer(s.hash))
+	}
+	return
+}
+
+// ReencryptInit sets up the reencryption of a LUKS2 device, unlocking
+// it with pass from keyslot slot (or any keyslot if slot is AnySlot).
+// If name is the name of the active mapping of the device, the
+// reencryption happens online, otherwise name should be nil.
+//
+// When reencrypting, the new volume key is stored in a new keyslot for
+// pass and for each of the Passphrases, the old keyslots are removed
+// once the reencryption finishes. ReencryptInit fails with
+// ErrKeyslotsLost if any other active keyslot is not unlocked by one
+// of the Passphrases, destroy such keyslots first to drop them.
+// When encrypting, slot is the keyslot of the new LUKS2 header that
+// pass unlocks.
+//
+// The data itself is only processed by Reencrypt.
+func (d *Device) ReencryptInit(name *string, pass []byte, slot int, p ReencryptParams) error {
+	if _, ok := <-firstInitStatus; ok {
+		defer close(firstInitStatus)
+	}
+
+	if p.Resilience == "" {
+		// the library has no default for a new reencryption
+		p.Resilience = ResilienceChecksum
+	}
+	// keep the cipher, key size and sector size of the device
+	if p.Luks2.Cipher == "" {
+		p.Luks2.Cipher = C.GoString(C.crypt_get_cipher(d.cd))
+	}
+	if p.Luks2.Mode == "" {
+		p.Luks2.Mode = C.GoString(C.crypt_get_cipher_mode(d.cd))
+	}
+	if p.Luks2.VolumeKeySize == 0 {
+		p.Luks2.VolumeKeySize = uint64(C.crypt_get_volume_key_size(d.cd))
+	}
+	if p.Luks2.SectorSize == 0 {
+		p.Luks2.SectorSize = uint32(C.crypt_get_sector_size(d.cd))
+	}
+	s, pp, free := p.c()
+	defer free()
+
+	oldSlot, newSlot := slot, AnySlot
+	cipher, mode := &pp.Cipher, &pp.Mode
+	var err error
+	switch p.Mode {
+	case ReencryptReencrypt:
+		var added []int
+		added, err = d.addReencryptKeyslots(pass, slot, pp.VolumeKeySize, p.Passphrases)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err != nil {
+				for _, k := range added {
+					d.keyslotDestroy(k)
+				}
+			}
+		}()
+		newSlot = added[0]
+	case ReencryptEncrypt:
+		oldSlot, newSlot = AnySlot, slot
+	case ReencryptDecrypt:
+		cipher, mode = nil, nil
+	}
+
+	_, err = d.reencryptInitByPassphrase(
+		name,
+		pass,
+		oldSlot,
+		newSlot,
+		cipher,
+		mode,
+		unsafe.Pointer(s),
+	)
+	return err
+}
+
+// addReencryptKeyslots adds keyslots for a new volume key, unbound
+// from the data, for pass and for the passphrases of every other
+// active keyslot. The keyslot of pass comes first.
+func (d *Device) addReencryptKeyslots(pass []byte, slot int, keySize uint64, passphrases [][]byte) (added []int, err error) {
+	slot, err = d.activateByPassphrase(nil, slot, pass, 0)
+	if err != nil {
+		return nil, err
+	}
+	keyslots, err := d.Keyslots()
+	if err != nil {
+		return nil, err
+	}
+	var keep [][]byte
+	for _, k := range keyslots {
+		if k.Slot == slot || (k.Status != KeyslotActive && k.Status != KeyslotActiveLast) {
+			continue
+		}
+		found := false
+		for _, p := range passphrases {
+			if _, err := d.activateByPassphrase(nil, k.Slot, p, 0); err == nil {
+				keep = append(keep, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrKeyslotsLost
+		}
+	}
+
+	defer func() {
+		if err != nil {
+			for _, k := range added {
+				d.keyslotDestroy(k)
+			}
+			added = nil
+		}
+	}()
+	k, err := d.keyslotAddByKey(AnySlot, nil, keySize, pass, C.CRYPT_VOLUME_KEY_NO_SEGMENT)
+	if err != nil {
+		return
+	}
+	added = append(added, k)
+	if len(keep) == 0 {
+		return
+	}
+
+	// the other keyslots must hold the same new volume key and
+	// share its digest
+	vk, err := d.VolumeKey(k, pass)
+	if err != nil {
+		return
+	}
+	defer Wipe(vk)
+	for _, p := range keep {
+		k, err = d.keyslotAddByKey(AnySlot, vk, uint64(len(vk)), p,
+			C.CRYPT_VOLUME_KEY_NO_SEGMENT|C.CRYPT_VOLUME_KEY_DIGEST_REUSE)
+		if err != nil {
+			return
+		}
+		added = append(added, k)
+	}
+	return
+}
+
+// ReencryptResume picks up a reencryption that was interrupted or set
+// up with ReencryptInitializeOnly. The data itself is only processed
+// by Reencrypt.
+func (d *Device) ReencryptResume(name *string, pass []byte, slot int) error {
+	s, _, free := ReencryptParams{Flags: C.CRYPT_REENCRYPT_RESUME_ONLY}.c()
+	defer free()
+	_, err := d.reencryptInitByPassphrase(
+		name,
+		pass,
+		slot,
+		AnySlot,
+		nil,
+		nil,
+		unsafe.Pointer(s),
+	)
+	return err
+}
+
+// ReencryptProgress is called periodically while reencrypting with
+// the size of the data to process and the offset reached so far (in
+// bytes). Returning false interrupts the reencryption, which can be
+// picked up later with ReencryptResume.
+type ReencryptProgress func(size, offset uint64) bool
+
+var progressFuncs = struct {
+	sync.Mutex
+	m    map[C.uintptr_t]ReencryptProgress
+	next C.uintptr_t
+}{m: make(map[C.uintptr_t]ReencryptProgress)}
+
+// Reencrypt runs the reencryption set up by ReencryptInit or
+// ReencryptResume, calling progress (if not nil) along the way.
+func (d *Device) Reencrypt(progress ReencryptProgress) error {
+	if progress == nil {
+		return d.reencryptRun(nil)
+	}
+
+	progressFuncs.Lock()
+	id := progressFuncs.next
+	progressFuncs.next++
+	progressFuncs.m[id] = progress
+	progressFuncs.Unlock()
+	defer func() {
+		progressFuncs.Lock()
+		delete(progressFuncs.m, id)
+		progressFuncs.Unlock()
+	}()
+
+	// the id is passed through C memory, as Go pointers cannot be
+	// kept by C code
+	usrptr := C.malloc(C.sizeof_uintptr_t)
+	defer C.free(usrptr)
+	*(*C.uintptr_t)(usrptr) = id
+	return d.reencryptRun(usrptr)
+}
+
+//export golang_gocrypt_reencrypt_progress
+func golang_gocrypt_reencrypt_progress(size, offset C.uint64_t, usrptr unsafe.Pointer) C.int {
+	if usrptr == nil {
+		return 0
+	}
+	progressFuncs.Lock()
+	progress := progressFuncs.m[*(*C.uintptr_t)(usrptr)]
+	progressFuncs.Unlock()
+	if progress == nil || progress(uint64(size), uint64(offset)) {
+		return 0
+	}
+	return 1
+}
+
+// ReencryptStatus returns the state of the reencryption of the device
+// and the parameters it was set up with.
+func (d *Device) ReencryptStatus() (ReencryptStatus, ReencryptParams) {
+	var s C.struct_crypt_params_reencrypt
+	status := ReencryptStatus(C.crypt_reencrypt_status(d.cd, &s))
+	return status, ReencryptParams{
+		Mode:           ReencryptMode(s.mode),
+		Direction:      ReencryptDirection(s.direction),
+		Resilience:     C.GoString(s.resilience),
+		Hash:           C.GoString(s.hash),
+		DataShift:      uint64(s.data_shift),
+		MaxHotzoneSize: uint64(s.max_hotzone_size),
+		DeviceSize:     uint64(s.device_size),
+		Flags:          ReencryptFlags(s.flags),
+	}
+}
@@ -0,0 +1,10 @@
+/* reencryption functions */
+
+#ifndef REENCRYPT_H
+#define REENCRYPT_H
+
+#include <stdint.h>
+
+int gocrypt_reencrypt_progress(uint64_t, uint64_t, void *);
+
+#endif /* REENCRYPT_H */
@@ -0,0 +1,184 @@
+package cryptsetup
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDevice_Reencrypt(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, Luks2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldKey, err := d.VolumeKey(AnySlot, mypassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.ReencryptInit(nil, mypassword, AnySlot, ReencryptParams{
+		Mode:           ReencryptReencrypt,
+		Resilience:     ResilienceChecksum,
+		MaxHotzoneSize: 1 << 20 / SectorSize,
+		Luks2: Luks2Params{
+			Params: Params{Mode: "cbc-essiv:sha256"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// interrupt the first run
+	calls := 0
+	err = d.Reencrypt(func(size, offset uint64) bool {
+		calls++
+		return offset == 0
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls == 0 {
+		t.Fatal("progress was never called")
+	}
+	status, p := d.ReencryptStatus()
+	if status != ReencryptClean || p.Mode != ReencryptReencrypt {
+		t.Fatalf("unexpected reencryption status %v %+v", status, p)
+	}
+
+	err = d.ReencryptResume(nil, mypassword, AnySlot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Reencrypt(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, _ = d.ReencryptStatus()
+	if status != ReencryptNone {
+		t.Fatalf("unexpected reencryption status %v", status)
+	}
+
+	if d.Params().Mode != "cbc-essiv:sha256" {
+		t.Fatalf("unexpected cipher mode %s", d.Params().Mode)
+	}
+	newKey, err := d.VolumeKey(AnySlot, mypassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(oldKey, newKey) {
+		t.Fatal("volume key was not changed")
+	}
+}
+
+func TestDevice_Reencrypt_keyslots(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	other := []byte("another password")
+	err = d.Format(mypassword, Luks2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.AddKey(mypassword, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the other keyslot would be lost
+	p := ReencryptParams{Mode: ReencryptReencrypt}
+	err = d.ReencryptInit(nil, mypassword, AnySlot, p)
+	if err != ErrKeyslotsLost {
+		t.Fatalf("got %v, want %v", err, ErrKeyslotsLost)
+	}
+	status, _ := d.ReencryptStatus()
+	if status != ReencryptNone {
+		t.Fatalf("unexpected reencryption status %v", status)
+	}
+
+	p.Passphrases = [][]byte{[]byte("wrong password"), other}
+	err = d.ReencryptInit(nil, mypassword, AnySlot, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.Reencrypt(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := d.VolumeKey(AnySlot, mypassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := d.VolumeKey(AnySlot, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(key, otherKey) {
+		t.Fatal("the passphrases unlock different volume keys")
+	}
+	keyslots, err := d.Keyslots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	active := 0
+	for _, k := range keyslots {
+		if k.InUse() {
+			active++
+		}
+	}
+	if active != 2 {
+		t.Fatalf("%d keyslots in use, want 2", active)
+	}
+}
+
+func TestDevice_Reencrypt_keepCipher(t *testing.T) {
+	t.Parallel()
+
+	for _, want := range []Params{
+		{Cipher: "aes", Mode: "xts-plain64", VolumeKeySize: 512 / 8},
+		{Cipher: "aes", Mode: "cbc-essiv:sha256", VolumeKeySize: 128 / 8},
+	} {
+		want := want
+		t.Run(want.Mode, func(t *testing.T) {
+			t.Parallel()
+
+			d, f, err := makeDeviceSize(luks2Size)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer freeme(d, f)
+
+			err = d.Format(mypassword, Luks2Params{Params: want})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// only rotate the volume key
+			err = d.ReencryptInit(nil, mypassword, AnySlot, ReencryptParams{Mode: ReencryptReencrypt})
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = d.Reencrypt(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := d.Params()
+			if got.Cipher != want.Cipher || got.Mode != want.Mode || got.VolumeKeySize != want.VolumeKeySize {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"syscall"
+	"unsafe"
+)
+
+// DeviceStatus is the state of a device-mapper mapping.
+type DeviceStatus int
+
+const (
+	StatusInvalid  DeviceStatus = C.CRYPT_INVALID
+	StatusInactive DeviceStatus = C.CRYPT_INACTIVE
+	StatusActive   DeviceStatus = C.CRYPT_ACTIVE
+	StatusBusy     DeviceStatus = C.CRYPT_BUSY // active and in use
+)
+
+func (s DeviceStatus) String() string {
+	switch s {
+	case StatusInactive:
+		return "inactive"
+	case StatusActive:
+		return "active"
+	case StatusBusy:
+		return "busy"
+	}
+	return "invalid"
+}
+
+// ActiveDevice describes a device-mapper mapping set up by one of the
+// Activate methods. All offsets and sizes are in 512 byte sectors.
+type ActiveDevice struct {
+	Status   DeviceStatus
+	Offset   uint64 // offset of the data on the underlying device
+	IvOffset uint64 // IV offset
+	Size     uint64 // size of the mapping
+	Flags    ActivateFlags
+
+	ReadOnly            bool
+	AllowDiscards       bool
+	SameCpuCrypt        bool
+	SubmitFromCryptCpus bool
+	NoReadWorkqueue     bool
+	NoWriteWorkqueue    bool
+	KeyringKey          bool // the volume key is in the kernel keyring
+	Suspended           bool
+
+	Integrity string // integrity algorithm or ""
+	TagSize   uint32 // integrity tag size (in bytes) per sector
+}
+
+// Status returns the state of the mapping called name. The remaining
+// fields of ActiveDevice are only filled in for active mappings.
+func (d *Device) Status(name string) (a ActiveDevice, err error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	a.Status = DeviceStatus(C.crypt_status(d.cd, cname))
+	switch a.Status {
+	case StatusInvalid:
+		return a, newError(-int(syscall.EINVAL), nil)
+	case StatusInactive:
+		return a, nil
+	}
+
+	var cad C.struct_crypt_active_device
+	err = d.getActiveDevice(name, &cad)
+	if err != nil {
+		return
+	}
+	a.Offset = uint64(cad.offset)
+	a.IvOffset = uint64(cad.iv_offset)
+	a.Size = uint64(cad.size)
+	a.Flags = ActivateFlags(cad.flags)
+	a.ReadOnly = a.Flags&ActivateReadOnly != 0
+	a.AllowDiscards = a.Flags&ActivateAllowDiscards != 0
+	a.SameCpuCrypt = a.Flags&ActivateSameCpuCrypt != 0
+	a.SubmitFromCryptCpus = a.Flags&ActivateSubmitFromCryptCpus != 0
+	a.NoReadWorkqueue = a.Flags&ActivateNoReadWorkqueue != 0
+	a.NoWriteWorkqueue = a.Flags&ActivateNoWriteWorkqueue != 0
+	a.KeyringKey = a.Flags&ActivateKeyringKey != 0
+	a.Suspended = a.Flags&ActivateSuspended != 0
+
+	// name may be the mapping of another device, so the integrity
+	// parameters are read from the active device itself
+	var cd *C.struct_crypt_device
+	if C.crypt_init_by_name(&cd, cname) >= 0 {
+		defer C.crypt_free(cd)
+		if ip, ok := (&Device{cd: cd}).IntegrityInfo(); ok {
+			a.Integrity = ip.Integrity
+			a.TagSize = ip.TagSize
+		}
+	}
+	return
+}
@@ -0,0 +1,137 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+// #include <stdlib.h>
+import "C"
+import (
+	"strings"
+	"unsafe"
+)
+
+// TcryptFlags select which TrueCrypt or VeraCrypt header is opened
+// and how it is searched for.
+type TcryptFlags uint32
+
+const (
+	// TcryptLegacyModes also tries the legacy CBC and LRW modes.
+	TcryptLegacyModes TcryptFlags = C.CRYPT_TCRYPT_LEGACY_MODES
+
+	// TcryptHiddenHeader opens the hidden volume.
+	TcryptHiddenHeader TcryptFlags = C.CRYPT_TCRYPT_HIDDEN_HEADER
+
+	// TcryptBackupHeader uses the backup header at the end of the
+	// device.
+	TcryptBackupHeader TcryptFlags = C.CRYPT_TCRYPT_BACKUP_HEADER
+
+	// TcryptSystemHeader opens a system encrypted disk or
+	// partition.
+	TcryptSystemHeader TcryptFlags = C.CRYPT_TCRYPT_SYSTEM_HEADER
+
+	// TcryptVeraModes also tries VeraCrypt headers.
+	TcryptVeraModes TcryptFlags = C.CRYPT_TCRYPT_VERA_MODES
+)
+
+// TcryptParams is the set of parameters used for opening TrueCrypt
+// and VeraCrypt containers. These can only be loaded, the header is
+// decrypted by Load and the container is then opened with
+// ActivateTcrypt.
+type TcryptParams struct {
+	Passphrase   []byte
+	Keyfiles     []string    // paths of the keyfiles or nil
+	Hash         string      // PBKDF2 hash to try or "" for all of them
+	VeracryptPim uint32      // VeraCrypt personal iterations multiplier or 0
+	Flags        TcryptFlags // which header to open
+}
+
+func (p TcryptParams) CMode() (t string, pp Params, out unsafe.Pointer, free func()) {
+	t = C.CRYPT_TCRYPT
+	s := C.struct_crypt_params_tcrypt{
+		passphrase:      nil,
+		passphrase_size: C.size_t(len(p.Passphrase)),
+		keyfiles:        nil,
+		keyfiles_count:  C.uint(len(p.Keyfiles)),
+		hash_name:       nil,
+		veracrypt_pim:   C.uint32_t(p.VeracryptPim),
+		flags:           C.uint32_t(p.Flags),
+	}
+	if p.Passphrase != nil {
+		s.passphrase = (*C.char)(C.CBytes(p.Passphrase))
+	}
+	if p.Hash != "" {
+		s.hash_name = C.CString(p.Hash)
+	}
+	var keyfiles []*C.char
+	if len(p.Keyfiles) > 0 {
+		size := C.size_t(len(p.Keyfiles)) * C.size_t(unsafe.Sizeof((*C.char)(nil)))
+		s.keyfiles = (**C.char)(C.malloc(size))
+		keyfiles = unsafe.Slice(s.keyfiles, len(p.Keyfiles))
+		for i, k := range p.Keyfiles {
+			keyfiles[i] = C.CString(k)
+		}
+	}
+	out = C.malloc(C.sizeof_struct_crypt_params_tcrypt)
+	*(*C.struct_crypt_params_tcrypt)(out) = s
+	free = func() {
+		Wipe(unsafe.Slice((*byte)(unsafe.Pointer(s.passphrase)), len(p.Passphrase)))
+		C.free(out)
+		// C.free is a no-op on nil pointers
+		C.free(unsafe.Pointer(s.passphrase))
+		C.free(unsafe.Pointer(s.hash_name))
+		for _, k := range keyfiles {
+			C.free(unsafe.Pointer(k))
+		}
+		C.free(unsafe.Pointer(s.keyfiles))
+	}
+	return
+}
+
+// ActivateTcrypt activates a TrueCrypt or VeraCrypt container loaded
+// with TcryptParams under the given name.
+func (d *Device) ActivateTcrypt(name string, flags ActivateFlags) error {
+	return d.activateByVolumeKey(&name, nil, d.activateFlags(flags))
+}
+
+// TcryptInfo describes the decrypted header of a TrueCrypt or
+// VeraCrypt container. Offsets are in 512 byte sectors.
+type TcryptInfo struct {
+	VeraCrypt  bool   // the header is a VeraCrypt header
+	Hash       string // PBKDF2 hash protecting the header
+	Cipher     string // cipher chain, e.g. "aes-twofish"
+	Mode       string // cipher mode
+	KeySize    uint64 // volume key size (in bytes)
+	DataOffset uint64 // offset of the data on the device
+	IvOffset   uint64 // IV offset
+}
+
+// TcryptInfo returns the header of the container loaded with
+// TcryptParams.
+func (d *Device) TcryptInfo() (TcryptInfo, error) {
+	// the hash is only reported by the library's dump
+	lines, err := d.dumpLines()
+	if err != nil {
+		return TcryptInfo{}, err
+	}
+	info := parseTcryptInfo(lines)
+	info.Cipher = C.GoString(C.crypt_get_cipher(d.cd))
+	info.Mode = C.GoString(C.crypt_get_cipher_mode(d.cd))
+	info.KeySize = uint64(C.crypt_get_volume_key_size(d.cd))
+	info.DataOffset = uint64(C.crypt_get_data_offset(d.cd))
+	info.IvOffset = uint64(C.crypt_get_iv_offset(d.cd))
+	return info, nil
+}
+
+// parseTcryptInfo reads the header type and hash out of the library's
+// dump of a TrueCrypt or VeraCrypt container, the rest of TcryptInfo
+// is left empty.
+func parseTcryptInfo(lines []string) (info TcryptInfo) {
+	for _, l := range lines {
+		if strings.HasPrefix(l, "VERACRYPT header") {
+			info.VeraCrypt = true
+		}
+		if k, v, ok := dumpField(l); ok && k == "PBKDF2 hash" {
+			info.Hash = v
+		}
+	}
+	return
+}
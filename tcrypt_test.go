@@ -0,0 +1,79 @@
+package cryptsetup
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDevice_Tcrypt_error(t *testing.T) {
+	t.Parallel()
+
+	d, f, err := makeDevice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	keyfile := makeKeyfile(t, "keyfile contents")
+	defer os.Remove(keyfile)
+
+	// an empty device does not decrypt to a header with any
+	// passphrase
+	err = d.Load(TcryptParams{
+		Passphrase: mypassword,
+		Keyfiles:   []string{keyfile},
+		Hash:       "sha512",
+	})
+	if err == nil {
+		t.Fatal("loaded a container from an empty device")
+	}
+}
+
+func Test_parseTcryptInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		dump string
+		want TcryptInfo
+	}{
+		{
+			name: "truecrypt",
+			dump: "TCRYPT header information for /dev/loop0\n" +
+				"Driver req.:\t7.0\n" +
+				"Sector size:\t512\n" +
+				"MK offset:\t256\n" +
+				"PBKDF2 hash:\tripemd160\n" +
+				"Cipher chain:\taes\n" +
+				"Cipher mode:\txts-plain64\n" +
+				"MK bits:       \t512\n",
+			want: TcryptInfo{Hash: "ripemd160"},
+		},
+		{
+			name: "veracrypt",
+			dump: "VERACRYPT header information for /dev/loop0\n" +
+				"Driver req.:\t1.b\n" +
+				"Sector size:\t512\n" +
+				"MK offset:\t256\n" +
+				"PBKDF2 hash:\tsha512\n" +
+				"Cipher chain:\taes-twofish\n" +
+				"Cipher mode:\txts-plain64\n" +
+				"MK bits:       \t1024\n",
+			want: TcryptInfo{VeraCrypt: true, Hash: "sha512"},
+		},
+		{
+			name: "empty",
+			dump: "",
+			want: TcryptInfo{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseTcryptInfo(strings.Split(tt.dump, "\n"))
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
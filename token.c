@@ -0,0 +1,17 @@
+/* token handler functions */
+
+#include "token.h"
+
+int gocrypt_token_open(struct crypt_device *cd, int token, char **buffer, size_t *buffer_len, void *usrptr) {
+  extern int golang_gocrypt_token_open(struct crypt_device *, int, char **, size_t *, void *);
+  return golang_gocrypt_token_open(cd, token, buffer, buffer_len, usrptr);
+}
+
+void gocrypt_token_buffer_free(void *buffer, size_t buffer_len) {
+  crypt_safe_free(buffer);
+}
+
+int gocrypt_token_validate(struct crypt_device *cd, const char *json) {
+  extern int golang_gocrypt_token_validate(struct crypt_device *, char *);
+  return golang_gocrypt_token_validate(cd, (char *) json);
+}
@@ -0,0 +1,279 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+// #include "token.h"
+// #include <stdlib.h>
+// #include <string.h>
+import "C"
+import (
+	"encoding/json"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// AnyToken lets the library try every token of the device.
+const AnyToken = C.CRYPT_ANY_TOKEN
+
+// TokenStatus is the state of a LUKS2 token.
+type TokenStatus int
+
+const (
+	TokenInvalid         TokenStatus = C.CRYPT_TOKEN_INVALID
+	TokenInactive        TokenStatus = C.CRYPT_TOKEN_INACTIVE
+	TokenInternal        TokenStatus = C.CRYPT_TOKEN_INTERNAL         // handled by the library
+	TokenInternalUnknown TokenStatus = C.CRYPT_TOKEN_INTERNAL_UNKNOWN // reserved type without a handler
+	TokenExternal        TokenStatus = C.CRYPT_TOKEN_EXTERNAL         // handled by a plugin or TokenHandler
+	TokenExternalUnknown TokenStatus = C.CRYPT_TOKEN_EXTERNAL_UNKNOWN // no handler for the type
+)
+
+func (s TokenStatus) String() string {
+	switch s {
+	case TokenInactive:
+		return "inactive"
+	case TokenInternal:
+		return "internal"
+	case TokenInternalUnknown:
+		return "internal-unknown"
+	case TokenExternal:
+		return "external"
+	case TokenExternalUnknown:
+		return "external-unknown"
+	}
+	return "invalid"
+}
+
+// Token describes a LUKS2 token in use.
+type Token struct {
+	ID       int
+	Status   TokenStatus
+	Type     string
+	Keyslots []int // keyslots the token is assigned to
+}
+
+// Tokens returns the tokens stored in the LUKS2 header of the device.
+func (d *Device) Tokens() ([]Token, error) {
+	max := int(C.crypt_token_max(C.crypt_get_type(d.cd)))
+	if max < 0 {
+		return nil, newError(max, nil)
+	}
+	slots := int(C.crypt_keyslot_max(C.crypt_get_type(d.cd)))
+
+	var out []Token
+	for i := 0; i < max; i++ {
+		var t *C.char
+		s := TokenStatus(C.crypt_token_status(d.cd, C.int(i), &t))
+		if s == TokenInvalid || s == TokenInactive {
+			continue
+		}
+		tok := Token{ID: i, Status: s, Type: C.GoString(t)}
+		for k := 0; k < slots; k++ {
+			if C.crypt_token_is_assigned(d.cd, C.int(i), C.int(k)) == 0 {
+				tok.Keyslots = append(tok.Keyslots, k)
+			}
+		}
+		out = append(out, tok)
+	}
+	return out, nil
+}
+
+// TokenJSON returns the JSON metadata of token id.
+func (d *Device) TokenJSON(id int) (string, error) {
+	var s *C.char
+	_, err := d.tokenJsonGet(id, &s)
+	if err != nil {
+		return "", err
+	}
+	return C.GoString(s), nil
+}
+
+// SetTokenJSON stores js as the JSON metadata of token id, replacing
+// any token with that id. Use AnyToken to store it in a free token. It
+// returns the id of the token.
+func (d *Device) SetTokenJSON(id int, js string) (int, error) {
+	return d.tokenJsonSet(id, &js)
+}
+
+// RemoveToken removes token id from the device.
+func (d *Device) RemoveToken(id int) error {
+	_, err := d.tokenJsonSet(id, nil)
+	return err
+}
+
+// AssignToken assigns token to keyslot, so that the passphrase
+// produced by the token is tried on that keyslot. Use AnySlot to
+// assign it to all keyslots.
+func (d *Device) AssignToken(token, keyslot int) error {
+	_, err := d.tokenAssignKeyslot(token, keyslot)
+	return err
+}
+
+// UnassignToken reverses AssignToken.
+func (d *Device) UnassignToken(token, keyslot int) error {
+	_, err := d.tokenUnassignKeyslot(token, keyslot)
+	return err
+}
+
+// ActivateByToken sets up the encrypted volume as name under the
+// directory specified by Dir(), unlocking it with the passphrase
+// produced by token id, or by any token if id is AnyToken. The pin is
+// passed on to the token handler and may be nil. If name is nil the
+// passphrase is only checked and nothing is activated. It returns the
+// keyslot that was unlocked.
+//
+// The library does not pass a pin to handlers registered in-process,
+// so when AnyToken is combined with a pin, TokenHandlers are skipped.
+func (d *Device) ActivateByToken(name *string, id int, pin []byte, flags ActivateFlags) (int, error) {
+	p := (*C.struct_gocrypt_token_pin)(C.malloc(C.sizeof_struct_gocrypt_token_pin))
+	defer C.free(unsafe.Pointer(p))
+	*p = C.struct_gocrypt_token_pin{}
+	if len(pin) > 0 {
+		// crypt_safe_free wipes the copy of the pin
+		p.pin = (*C.char)(C.crypt_safe_alloc(C.size_t(len(pin))))
+		if p.pin == nil {
+			return 0, newError(-int(syscall.ENOMEM), nil)
+		}
+		defer C.crypt_safe_free(unsafe.Pointer(p.pin))
+		C.memcpy(unsafe.Pointer(p.pin), unsafe.Pointer(&pin[0]), C.size_t(len(pin)))
+		p.pin_size = C.size_t(len(pin))
+	}
+
+	// hand the pin to our own handlers through usrptr instead
+	if id != AnyToken {
+		var t *C.char
+		C.crypt_token_status(d.cd, C.int(id), &t)
+		if lookupTokenHandler(C.GoString(t)) != nil {
+			pin = nil
+		}
+	}
+
+	return d.activateByTokenPin(name, nil, id, pin, unsafe.Pointer(p), d.activateFlags(flags))
+}
+
+// TokenHandler implements a LUKS2 token type in Go. Once registered
+// with RegisterTokenHandler, the library calls it for every token
+// whose "type" matches the name it was registered with.
+type TokenHandler interface {
+	// Open returns the passphrase for the keyslots assigned to
+	// token id, whose JSON metadata is js. The pin is the one
+	// passed to ActivateByToken and may be nil.
+	//
+	// The pin is wiped once Open returns and the returned
+	// passphrase is wiped once the library has a copy of it, so
+	// neither may be kept or shared.
+	Open(id int, js string, pin []byte) ([]byte, error)
+
+	// Validate checks the JSON metadata of a token before it is
+	// stored in the header.
+	Validate(js string) error
+}
+
+var tokenHandlers = struct {
+	sync.RWMutex
+	m map[string]TokenHandler
+}{m: make(map[string]TokenHandler)}
+
+func lookupTokenHandler(name string) TokenHandler {
+	tokenHandlers.RLock()
+	defer tokenHandlers.RUnlock()
+	return tokenHandlers.m[name]
+}
+
+// RegisterTokenHandler registers h as the handler for tokens of type
+// name. Handlers cannot be unregistered, and the library only has
+// room for a few of them.
+func RegisterTokenHandler(name string, h TokenHandler) error {
+	tokenHandlers.Lock()
+	defer tokenHandlers.Unlock()
+	if _, ok := tokenHandlers.m[name]; ok {
+		return newError(-int(syscall.EEXIST), nil)
+	}
+
+	// the library keeps a pointer to the handler, so it is never
+	// freed
+	ch := (*C.crypt_token_handler)(C.malloc(C.sizeof_crypt_token_handler))
+	*ch = C.crypt_token_handler{
+		name:        C.CString(name),
+		open:        (*[0]byte)(C.gocrypt_token_open),
+		buffer_free: (*[0]byte)(C.gocrypt_token_buffer_free),
+		validate:    (*[0]byte)(C.gocrypt_token_validate),
+	}
+	r := int(C.crypt_token_register(ch))
+	if r < 0 {
+		C.free(unsafe.Pointer(ch.name))
+		C.free(unsafe.Pointer(ch))
+		return newError(r, nil)
+	}
+	tokenHandlers.m[name] = h
+	return nil
+}
+
+// tokenErrno converts an error from a TokenHandler into the negative
+// errno expected by the library.
+func tokenErrno(err error) C.int {
+	switch e := err.(type) {
+	case syscall.Errno:
+		return -C.int(e)
+	case CryptError:
+		if errno, ok := e.Errno.(syscall.Errno); ok {
+			return -C.int(errno)
+		}
+	}
+	return -C.int(syscall.EINVAL)
+}
+
+//export golang_gocrypt_token_open
+func golang_gocrypt_token_open(cd *C.struct_crypt_device, token C.int, buffer **C.char, bufferLen *C.size_t, usrptr unsafe.Pointer) C.int {
+	var t, js *C.char
+	C.crypt_token_status(cd, token, &t)
+	h := lookupTokenHandler(C.GoString(t))
+	if h == nil {
+		return -C.int(syscall.ENOENT)
+	}
+	if r := C.crypt_token_json_get(cd, token, &js); r < 0 {
+		return r
+	}
+
+	var pin []byte
+	if p := (*C.struct_gocrypt_token_pin)(usrptr); p != nil && p.pin != nil {
+		pin = C.GoBytes(unsafe.Pointer(p.pin), C.int(p.pin_size))
+		defer Wipe(pin)
+	}
+	pass, err := h.Open(int(token), C.GoString(js), pin)
+	if err != nil {
+		return tokenErrno(err)
+	}
+	if len(pass) == 0 {
+		return -C.int(syscall.EINVAL)
+	}
+
+	// freed by gocrypt_token_buffer_free
+	*buffer = (*C.char)(C.crypt_safe_alloc(C.size_t(len(pass))))
+	if *buffer == nil {
+		return -C.int(syscall.ENOMEM)
+	}
+	C.memcpy(unsafe.Pointer(*buffer), unsafe.Pointer(&pass[0]), C.size_t(len(pass)))
+	*bufferLen = C.size_t(len(pass))
+	Wipe(pass)
+	return 0
+}
+
+//export golang_gocrypt_token_validate
+func golang_gocrypt_token_validate(cd *C.struct_crypt_device, js *C.char) C.int {
+	s := C.GoString(js)
+	var t struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(s), &t); err != nil {
+		return -C.int(syscall.EINVAL)
+	}
+	h := lookupTokenHandler(t.Type)
+	if h == nil {
+		return -C.int(syscall.ENOENT)
+	}
+	if err := h.Validate(s); err != nil {
+		return tokenErrno(err)
+	}
+	return 0
+}
@@ -0,0 +1,17 @@
+/* token handler functions */
+
+#ifndef TOKEN_H
+#define TOKEN_H
+
+#include <libcryptsetup.h>
+
+struct gocrypt_token_pin {
+  char *pin;
+  size_t pin_size;
+};
+
+int gocrypt_token_open(struct crypt_device *, int, char **, size_t *, void *);
+void gocrypt_token_buffer_free(void *, size_t);
+int gocrypt_token_validate(struct crypt_device *, const char *);
+
+#endif /* TOKEN_H */
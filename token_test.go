@@ -0,0 +1,105 @@
+package cryptsetup
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testTokenHandler struct{}
+
+func (testTokenHandler) Open(id int, js string, pin []byte) ([]byte, error) {
+	if string(pin) != "1234" {
+		return nil, errors.New("wrong pin")
+	}
+	return append([]byte(nil), mypassword...), nil
+}
+
+func (testTokenHandler) Validate(js string) error {
+	if !strings.Contains(js, `"keyslots"`) {
+		return errors.New("no keyslots")
+	}
+	return nil
+}
+
+// token handlers cannot be unregistered, so the test handler is only
+// registered once per process (e.g. with -count=2)
+var registerTestToken = sync.OnceValue(func() error {
+	return RegisterTokenHandler("go-cryptsetup-test", testTokenHandler{})
+})
+
+func TestDevice_Tokens(t *testing.T) {
+	t.Parallel()
+
+	err := registerTestToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, f, err := makeDeviceSize(luks2Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(mypassword, Luks2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := d.SetTokenJSON(AnyToken, `{"type":"go-cryptsetup-test","keyslots":[]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = d.AssignToken(id, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	js, err := d.TokenJSON(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(js, `"go-cryptsetup-test"`) {
+		t.Fatalf("unexpected token metadata %s", js)
+	}
+
+	tokens, err := d.Tokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].ID != id || tokens[0].Type != "go-cryptsetup-test" ||
+		len(tokens[0].Keyslots) != 1 || tokens[0].Keyslots[0] != 0 {
+		t.Fatalf("unexpected tokens %+v", tokens)
+	}
+
+	slot, err := d.ActivateByToken(nil, id, []byte("1234"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot != 0 {
+		t.Fatalf("token unlocked keyslot %d", slot)
+	}
+	_, err = d.ActivateByToken(nil, id, []byte("4321"), 0)
+	if err == nil {
+		t.Fatal("token opened with the wrong pin")
+	}
+
+	_, err = d.SetTokenJSON(AnyToken, `{"type":"go-cryptsetup-test"}`)
+	if err == nil {
+		t.Fatal("stored a token that does not validate")
+	}
+
+	err = d.RemoveToken(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err = d.Tokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 0 {
+		t.Fatalf("unexpected tokens %+v", tokens)
+	}
+}
@@ -0,0 +1,150 @@
+package cryptsetup
+
+// #cgo pkg-config: libcryptsetup
+// #include <libcryptsetup.h>
+// #include <stdlib.h>
+import "C"
+import "unsafe"
+
+// VerityFlags modify how a dm-verity device is handled.
+type VerityFlags uint32
+
+const (
+	// VerityNoHeader does not read or write the verity superblock
+	// on the hash device, all parameters must then be given.
+	VerityNoHeader VerityFlags = C.CRYPT_VERITY_NO_HEADER
+
+	// VerityCheckHash makes activation check the whole hash tree
+	// against the data first, Verify always does.
+	VerityCheckHash VerityFlags = C.CRYPT_VERITY_CHECK_HASH
+
+	// VerityRootHashSignature requires the root hash to be signed
+	// on activation.
+	VerityRootHashSignature VerityFlags = C.CRYPT_VERITY_ROOT_HASH_SIGNATURE
+)
+
+// VerityParams is the set of parameters used for dm-verity devices,
+// which provide read-only access to data protected by a hash tree.
+// The Device is opened on the hash device and the data lives on
+// DataDevice.
+//
+// Formatting computes the hash tree of the data, whose root hash is
+// then returned by RootHash. There are no keyslots, so Format ignores
+// its key.
+type VerityParams struct {
+	HashName       string // hash algorithm, DefaultHash if ""
+	DataDevice     string // device holding the protected data
+	FecDevice      string // device for forward error correction or ""
+	Salt           []byte // salt, 32 random bytes if nil
+	LegacyFormat   bool   // use the original Chrome OS format (type 0)
+	DataBlockSize  uint32 // data block size (in bytes), 4096 if 0
+	HashBlockSize  uint32 // hash block size (in bytes), 4096 if 0
+	DataSize       uint64 // number of data blocks, 0 for all of DataDevice
+	HashAreaOffset uint64 // offset of the hash area (in bytes)
+	FecAreaOffset  uint64 // offset of the error correction area (in bytes)
+	FecRoots       uint32 // number of error correction roots, 2 if 0
+	Flags          VerityFlags
+}
+
+func (p VerityParams) CMode() (t string, pp Params, out unsafe.Pointer, free func()) {
+	if p.HashName == "" {
+		p.HashName = DefaultHash
+	}
+	if p.DataBlockSize == 0 {
+		p.DataBlockSize = 4096
+	}
+	if p.HashBlockSize == 0 {
+		p.HashBlockSize = 4096
+	}
+	if p.FecRoots == 0 {
+		p.FecRoots = 2
+	}
+
+	t = C.CRYPT_VERITY
+	s := C.struct_crypt_params_verity{
+		hash_name:        C.CString(p.HashName),
+		data_device:      nil,
+		hash_device:      nil,
+		fec_device:       nil,
+		salt:             nil,
+		salt_size:        32,
+		hash_type:        1,
+		data_block_size:  C.uint32_t(p.DataBlockSize),
+		hash_block_size:  C.uint32_t(p.HashBlockSize),
+		data_size:        C.uint64_t(p.DataSize),
+		hash_area_offset: C.uint64_t(p.HashAreaOffset),
+		fec_area_offset:  C.uint64_t(p.FecAreaOffset),
+		fec_roots:        C.uint32_t(p.FecRoots),
+		flags:            C.uint32_t(p.Flags),
+	}
+	if p.DataDevice != "" {
+		s.data_device = C.CString(p.DataDevice)
+	}
+	if p.FecDevice != "" {
+		s.fec_device = C.CString(p.FecDevice)
+	}
+	if p.Salt != nil {
+		s.salt = (*C.char)(C.CBytes(p.Salt))
+		s.salt_size = C.uint32_t(len(p.Salt))
+	}
+	if p.LegacyFormat {
+		s.hash_type = 0
+	}
+	// formatting is what computes the hash tree, the flag is
+	// ignored otherwise
+	s.flags |= C.CRYPT_VERITY_CREATE_HASH
+	out = C.malloc(C.sizeof_struct_crypt_params_verity)
+	*(*C.struct_crypt_params_verity)(out) = s
+	free = func() {
+		C.free(out)
+		// C.free is a no-op on nil pointers
+		C.free(unsafe.Pointer(s.hash_name))
+		C.free(unsafe.Pointer(s.data_device))
+		C.free(unsafe.Pointer(s.fec_device))
+		C.free(unsafe.Pointer(s.salt))
+	}
+	return
+}
+
+// RootHash returns the root hash of a dm-verity device formatted with
+// VerityParams. It is not stored on the device, so it is only known
+// right after formatting.
+func (d *Device) RootHash() ([]byte, error) {
+	return d.VolumeKey(AnySlot, nil)
+}
+
+// ActivateByRootHash activates a dm-verity device under the given
+// name, read-only. If the device was loaded with
+// VerityRootHashSignature, signature is the PKCS#7 signature of the
+// root hash, checked by the kernel against its trusted keys, it
+// should be nil otherwise.
+func (d *Device) ActivateByRootHash(name string, rootHash, signature []byte) error {
+	return d.activateBySignedKey(&name, rootHash, signature, C.CRYPT_ACTIVATE_READONLY)
+}
+
+// Verify checks the data of a dm-verity device against its hash tree
+// and rootHash without activating it.
+func (d *Device) Verify(rootHash []byte) error {
+	var p C.struct_crypt_params_verity
+	if r := C.crypt_get_verity_info(d.cd, &p); r < 0 {
+		return newError(int(r), nil)
+	}
+
+	// the library only checks the hash tree of devices loaded with
+	// CRYPT_VERITY_CHECK_HASH, so the device is loaded again with
+	// it in a context of its own
+	v, err := NewDevice(C.GoString(p.hash_device))
+	if err != nil {
+		return err
+	}
+	defer v.Close()
+	p.flags |= C.CRYPT_VERITY_CHECK_HASH
+	// the signature is only checked by the kernel on activation
+	p.flags &^= C.CRYPT_VERITY_ROOT_HASH_SIGNATURE
+	t := C.CRYPT_VERITY
+	err = v.load(&t, unsafe.Pointer(&p))
+	if err != nil {
+		return err
+	}
+	return v.activateByVolumeKey(nil, rootHash, C.CRYPT_ACTIVATE_READONLY)
+}
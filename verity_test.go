@@ -0,0 +1,141 @@
+package cryptsetup
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestDevice_Verity(t *testing.T) {
+	t.Parallel()
+
+	data, err := ioutil.TempFile("", "go-cryptsetup_verity_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(data.Name())
+	block := make([]byte, 4096)
+	for i := 0; i < 256; i++ {
+		for j := range block {
+			block[j] = byte(i + j)
+		}
+		_, err = data.Write(block)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = data.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, f, err := makeDeviceSize(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(nil, VerityParams{DataDevice: data.Name()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := d.RootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rootHash) != 256/8 {
+		t.Fatalf("unexpected root hash size %d", len(rootHash))
+	}
+
+	// the hash tree is checked even without VerityCheckHash
+	verify := func(rootHash []byte) error {
+		d, err := NewDevice(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer d.Close()
+		err = d.Load(VerityParams{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = d.SetDataDevice(data.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d.Verify(rootHash)
+	}
+	err = verify(rootHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = verify(make([]byte, len(rootHash)))
+	if err == nil {
+		t.Fatal("verified against the wrong root hash")
+	}
+
+	// corrupt a single byte of the data
+	df, err := os.OpenFile(data.Name(), os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = df.WriteAt([]byte{0}, 4096*100+7)
+	df.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = verify(rootHash)
+	if err == nil {
+		t.Fatal("corrupted data was verified")
+	}
+}
+
+func TestDevice_Verity_activate(t *testing.T) {
+	t.Parallel()
+
+	if os.Geteuid() != 0 {
+		t.Skip("only root can activate a device")
+	}
+
+	data, err := ioutil.TempFile("", "go-cryptsetup_verity_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(data.Name())
+	_, err = data.Write(make([]byte, 1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = data.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, f, err := makeDeviceSize(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer freeme(d, f)
+
+	err = d.Format(nil, VerityParams{DataDevice: data.Name()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootHash, err := d.RootHash()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	name := "example_verity_device"
+	err = d.ActivateByRootHash(name, rootHash, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := d.Status(name)
+	d.Deactivate(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.ReadOnly {
+		t.Fatalf("unexpected status %+v", s)
+	}
+}